@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,6 +13,8 @@ import (
 	usersclient "github.com/ozankenangungor/go-commerce/internal/gateway/clients/users"
 	"github.com/ozankenangungor/go-commerce/internal/gateway/config"
 	gatewayhttp "github.com/ozankenangungor/go-commerce/internal/gateway/http"
+	gatewaymiddleware "github.com/ozankenangungor/go-commerce/internal/gateway/http/middleware"
+	"github.com/ozankenangungor/go-commerce/pkg/tracing"
 	"github.com/rs/zerolog"
 )
 
@@ -21,13 +25,47 @@ func main() {
 		os.Exit(1)
 	}
 
-	logger, err := newLogger(cfg.LogLevel)
+	logger, err := newLogger(cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "configure logger: %v\n", err)
 		os.Exit(1)
 	}
 
-	usersClient, err := usersclient.NewClient(context.Background(), cfg.UserServiceGRPCAddr, cfg.GRPCDialTimeout)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: "api-gateway",
+		Endpoint:    cfg.OTLPEndpoint,
+		Insecure:    cfg.OTLPInsecure,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to initialize tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if flushErr := shutdownTracing(flushCtx); flushErr != nil {
+			logger.Error().Err(flushErr).Msg("failed to flush traces")
+		}
+	}()
+
+	var usersTLS *tls.Config
+	if cfg.UserServiceTLSEnabled {
+		usersTLS, err = usersclient.LoadTLSConfig(cfg.UserServiceTLSCAFile)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to load users grpc tls config")
+			os.Exit(1)
+		}
+	}
+
+	usersClient, err := usersclient.NewClient(context.Background(), usersclient.DialConfig{
+		Addr:                cfg.UserServiceGRPCAddr,
+		DialTimeout:         cfg.GRPCDialTimeout,
+		TLSConfig:           usersTLS,
+		MaxRecvMsgSize:      cfg.GRPCMaxRecvMsgSize,
+		KeepaliveTime:       cfg.GRPCKeepaliveTime,
+		KeepaliveTimeout:    cfg.GRPCKeepaliveTimeout,
+		ValidateMaxAttempts: cfg.GRPCValidateAttempts,
+	})
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to initialize users grpc client")
 		os.Exit(1)
@@ -38,9 +76,36 @@ func main() {
 		}
 	}()
 
+	// AUTH_BREAKER_FAILURE_THRESHOLD=0 disables circuit breaking. The breaker sits under the
+	// cache so cached validations keep working while the user service is down.
+	breaker := usersclient.NewCircuitBreakingValidator(usersClient, usersclient.CircuitBreakerOptions{
+		FailureThreshold: cfg.AuthBreakerFailures,
+		OpenTimeout:      cfg.AuthBreakerOpenTime,
+	})
+
+	// AUTH_CACHE_SIZE=0 disables validation caching.
+	var tokenValidator gatewaymiddleware.TokenValidator = breaker
+	if cfg.AuthCacheSize > 0 {
+		tokenValidator = usersclient.NewCachingValidator(breaker, usersclient.ValidationCacheOptions{
+			Size:   cfg.AuthCacheSize,
+			MaxTTL: cfg.AuthCacheMaxTTL,
+		})
+	}
+
+	// PROFILE_CACHE_SIZE=0 disables profile caching.
+	var profileClient gatewayhttp.ProfileClient = usersClient
+	if cfg.ProfileCacheSize > 0 {
+		profileClient = usersclient.NewCachingProfileFetcher(usersClient, usersclient.ProfileCacheOptions{
+			Size: cfg.ProfileCacheSize,
+			TTL:  cfg.ProfileCacheTTL,
+		})
+	}
+
 	server := gatewayhttp.NewServer(cfg, gatewayhttp.Dependencies{
 		Logger:         logger,
-		TokenValidator: usersClient,
+		TokenValidator: tokenValidator,
+		AuthClient:     usersClient,
+		ProfileClient:  profileClient,
 		AuthRPCTimeout: cfg.AuthRPCTimeout,
 	})
 
@@ -64,7 +129,7 @@ func main() {
 		return
 	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownDrainDelay+5*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
@@ -78,18 +143,25 @@ func main() {
 			logger.Error().Err(err).Msg("api gateway exited with error")
 			os.Exit(1)
 		}
-	case <-time.After(6 * time.Second):
+	case <-time.After(cfg.ShutdownDrainDelay + 6*time.Second):
 		logger.Warn().Msg("timeout waiting for server goroutine to exit")
 	}
 }
 
-func newLogger(level string) (zerolog.Logger, error) {
+// newLogger builds the service logger. The console format is for local development; it is
+// human-readable but slower and not machine-parseable.
+func newLogger(level, format string) (zerolog.Logger, error) {
 	parsedLevel, err := zerolog.ParseLevel(level)
 	if err != nil {
 		return zerolog.Logger{}, fmt.Errorf("parse LOG_LEVEL: %w", err)
 	}
 
-	logger := zerolog.New(os.Stdout).
+	var out io.Writer = os.Stdout
+	if format == "console" {
+		out = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	}
+
+	logger := zerolog.New(out).
 		With().
 		Timestamp().
 		Str("service", "api-gateway").
@@ -3,15 +3,20 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	useradmin "github.com/ozankenangungor/go-commerce/internal/user/admin"
 	userconfig "github.com/ozankenangungor/go-commerce/internal/user/config"
 	userdb "github.com/ozankenangungor/go-commerce/internal/user/db"
 	usergrpc "github.com/ozankenangungor/go-commerce/internal/user/grpc"
 	userhandlers "github.com/ozankenangungor/go-commerce/internal/user/grpc/handlers"
+	userhttp "github.com/ozankenangungor/go-commerce/internal/user/http"
+	"github.com/ozankenangungor/go-commerce/internal/user/httpserver"
+	"github.com/ozankenangungor/go-commerce/pkg/tracing"
 	"github.com/rs/zerolog"
 )
 
@@ -22,29 +27,80 @@ func main() {
 		os.Exit(1)
 	}
 
-	logger, err := newLogger(cfg.LogLevel)
+	logger, err := newLogger(cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "configure logger: %v\n", err)
 		os.Exit(1)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: "user-service",
+		Endpoint:    cfg.OTLPEndpoint,
+		Insecure:    cfg.OTLPInsecure,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to initialize tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if flushErr := shutdownTracing(flushCtx); flushErr != nil {
+			logger.Error().Err(flushErr).Msg("failed to flush traces")
+		}
+	}()
+
+	// USER_DB_CONNECT_TIMEOUT bounds how long startup waits for Postgres to come up.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DBConnectTimeout)
 	defer cancel()
 
-	dbPool, err := userdb.NewPool(ctx, cfg.UserDBDSN, cfg.UserDBMaxConns)
+	dbPool, err := userdb.NewPool(ctx, cfg.UserDBDSN, userdb.PoolOptions{
+		MaxConns:          cfg.UserDBMaxConns,
+		MaxConnLifetime:   cfg.UserDBMaxConnLifetime,
+		MaxConnIdleTime:   cfg.UserDBMaxConnIdleTime,
+		HealthCheckPeriod: cfg.UserDBHealthCheckPeriod,
+	}, userdb.ConnectRetry{
+		MaxAttempts:    cfg.DBConnectMaxAttempts,
+		InitialBackoff: cfg.DBConnectBackoff,
+		MaxBackoff:     cfg.DBConnectMaxBackoff,
+	}, logger)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to initialize db pool")
 		os.Exit(1)
 	}
 	defer dbPool.Close()
 
-	if err := userdb.RunMigrations(cfg.UserDBDSN, cfg.MigrationsPath); err != nil {
+	// Opt-in: only builds stamped with an expected checksum verify the migrations on disk.
+	// Embedded migrations are part of the build itself, so there is nothing to verify.
+	if !cfg.MigrationsEmbedded && userdb.ExpectedMigrationsChecksum != "" {
+		if err := userdb.VerifyMigrationsChecksum(cfg.MigrationsPath, userdb.ExpectedMigrationsChecksum); err != nil {
+			logger.Error().Err(err).Msg("migrations do not match this build")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.MigrationsEmbedded {
+		err = userdb.RunMigrationsFS(cfg.UserDBDSN, userdb.Migrations())
+	} else {
+		err = userdb.RunMigrations(cfg.UserDBDSN, cfg.MigrationsPath)
+	}
+	if err != nil {
 		logger.Error().Err(err).Msg("failed to run migrations")
 		os.Exit(1)
 	}
 
 	handler := userhandlers.NewUserService(logger, dbPool)
-	grpcServer, err := usergrpc.NewServer(cfg.UserServiceGRPCAddr, logger, handler)
+	grpcServer, err := usergrpc.NewServer(cfg.UserServiceGRPCAddr, logger, handler, usergrpc.ServerOptions{
+		MaxConcurrentStreams: cfg.GRPCMaxConcurrentStreams,
+		MinRemainingDeadline: cfg.GRPCMinRemainingDeadline,
+		MaxRecvMsgSize:       cfg.GRPCMaxRecvMsgSize,
+		MaxSendMsgSize:       cfg.GRPCMaxSendMsgSize,
+		KeepaliveTime:        cfg.GRPCKeepaliveTime,
+		KeepaliveTimeout:     cfg.GRPCKeepaliveTimeout,
+		KeepaliveMinTime:     cfg.GRPCKeepaliveMinTime,
+		ReadinessCheck:       dbPool.Ping,
+		ReadinessInterval:    cfg.ReadinessInterval,
+	})
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to create grpc server")
 		os.Exit(1)
@@ -55,6 +111,33 @@ func main() {
 		serverErr <- grpcServer.Start()
 	}()
 
+	// Side HTTP listeners are opt-in: USER_SERVICE_ADMIN_ADDR enables the admin surface and
+	// USER_SERVICE_HTTP_ADDR the probe/metrics surface.
+	var listeners []*httpserver.Server
+	if cfg.AdminHTTPAddr != "" {
+		adminServer, err := useradmin.NewServer(cfg.AdminHTTPAddr, logger, dbPool, cfg.AdminToken)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to create admin server")
+			os.Exit(1)
+		}
+		listeners = append(listeners, adminServer)
+	}
+	if cfg.HTTPAddr != "" {
+		httpServer, err := userhttp.NewServer(cfg.HTTPAddr, logger, dbPool)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to create http server")
+			os.Exit(1)
+		}
+		listeners = append(listeners, httpServer)
+	}
+
+	listenerErr := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func() {
+			listenerErr <- listener.Start()
+		}()
+	}
+
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
 	defer signal.Stop(signalCh)
@@ -68,11 +151,23 @@ func main() {
 			os.Exit(1)
 		}
 		return
+	case err := <-listenerErr:
+		if err != nil {
+			logger.Error().Err(err).Msg("http listener exited unexpectedly")
+			os.Exit(1)
+		}
+		return
 	}
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer shutdownCancel()
 
+	for _, listener := range listeners {
+		if err := listener.Shutdown(shutdownCtx); err != nil {
+			logger.Error().Err(err).Msg("http listener shutdown failed")
+		}
+	}
+
 	if err := grpcServer.Shutdown(shutdownCtx); err != nil && err != context.DeadlineExceeded {
 		logger.Error().Err(err).Msg("grpc shutdown failed")
 		os.Exit(1)
@@ -89,13 +184,20 @@ func main() {
 	}
 }
 
-func newLogger(level string) (zerolog.Logger, error) {
+// newLogger builds the service logger. The console format is for local development; it is
+// human-readable but slower and not machine-parseable.
+func newLogger(level, format string) (zerolog.Logger, error) {
 	parsedLevel, err := zerolog.ParseLevel(level)
 	if err != nil {
 		return zerolog.Logger{}, fmt.Errorf("parse LOG_LEVEL: %w", err)
 	}
 
-	logger := zerolog.New(os.Stdout).
+	var out io.Writer = os.Stdout
+	if format == "console" {
+		out = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	}
+
+	logger := zerolog.New(out).
 		With().
 		Timestamp().
 		Str("service", "user-service").
@@ -0,0 +1,137 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen is returned while the circuit breaker is open. It carries codes.Unavailable
+// so callers map it exactly like an unreachable user service.
+var ErrCircuitOpen = status.Error(codes.Unavailable, "user service circuit breaker is open")
+
+// CircuitBreakerOptions configures CircuitBreakingValidator.
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive unavailability failures that opens the
+	// circuit. Zero disables the breaker.
+	FailureThreshold int
+
+	// OpenTimeout is how long the circuit stays open before a single probe call is let
+	// through to test whether the user service has recovered.
+	OpenTimeout time.Duration
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreakingValidator fails ValidateAccessToken fast with ErrCircuitOpen once the user
+// service has been unavailable for FailureThreshold calls in a row, instead of making every
+// request wait out the auth RPC timeout. Contract errors such as an invalid token count as
+// successes: the service answered.
+type CircuitBreakingValidator struct {
+	next identityValidator
+	opts CircuitBreakerOptions
+	now  func() time.Time
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreakingValidator wraps next with a circuit breaker.
+func NewCircuitBreakingValidator(next identityValidator, opts CircuitBreakerOptions) *CircuitBreakingValidator {
+	return newCircuitBreakingValidator(next, opts, time.Now)
+}
+
+func newCircuitBreakingValidator(next identityValidator, opts CircuitBreakerOptions, now func() time.Time) *CircuitBreakingValidator {
+	return &CircuitBreakingValidator{next: next, opts: opts, now: now}
+}
+
+// ValidateAccessToken validates a bearer token through the breaker.
+func (b *CircuitBreakingValidator) ValidateAccessToken(ctx context.Context, accessToken string, requestID string) (string, []string, error) {
+	identity, err := b.ValidateAccessTokenIdentity(ctx, accessToken, requestID)
+	if err != nil {
+		return "", nil, err
+	}
+	return identity.UserID, identity.Roles, nil
+}
+
+// ValidateAccessTokenIdentity validates a bearer token through the breaker and returns the
+// full identity envelope.
+func (b *CircuitBreakingValidator) ValidateAccessTokenIdentity(ctx context.Context, accessToken string, requestID string) (Identity, error) {
+	if !b.allow() {
+		return Identity{}, ErrCircuitOpen
+	}
+
+	identity, err := b.next.ValidateAccessTokenIdentity(ctx, accessToken, requestID)
+	b.record(ctx, err)
+	return identity, err
+}
+
+// allow reports whether a call may go through, moving an expired open circuit to half-open
+// and admitting exactly one probe.
+func (b *CircuitBreakingValidator) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.opts.OpenTimeout {
+			return false
+		}
+		b.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreakingValidator) record(ctx context.Context, err error) {
+	if b.opts.FailureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The caller giving up says nothing about the user service's health; a canceled probe
+	// just lets the next call probe again.
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		if b.state == circuitHalfOpen {
+			b.state = circuitOpen
+		}
+		return
+	}
+	if err == nil || !isServiceUnavailable(err) {
+		b.state = circuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.opts.FailureThreshold {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+func isServiceUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
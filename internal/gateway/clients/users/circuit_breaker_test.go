@@ -0,0 +1,85 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	next := &countingValidator{err: status.Error(codes.Unavailable, "connection refused")}
+	breaker := newCircuitBreakingValidator(next, CircuitBreakerOptions{FailureThreshold: 2, OpenTimeout: 10 * time.Second}, clock.Now)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := breaker.ValidateAccessToken(context.Background(), "token", "req"); status.Code(err) != codes.Unavailable {
+			t.Fatalf("call %d: expected Unavailable, got %v", i, err)
+		}
+	}
+
+	_, _, err := breaker.ValidateAccessToken(context.Background(), "token", "req")
+	if !errors.Is(err, ErrCircuitOpen) || status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected ErrCircuitOpen mapped to Unavailable, got %v", err)
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected an open circuit to skip the rpc, got %d calls", next.calls)
+	}
+}
+
+func TestCircuitBreakerProbesAndCloses(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	next := &countingValidator{err: status.Error(codes.DeadlineExceeded, "timeout")}
+	breaker := newCircuitBreakingValidator(next, CircuitBreakerOptions{FailureThreshold: 1, OpenTimeout: 10 * time.Second}, clock.Now)
+
+	_, _, _ = breaker.ValidateAccessToken(context.Background(), "token", "req")
+
+	// A failed probe re-opens the circuit for another full timeout.
+	clock.now = clock.now.Add(10 * time.Second)
+	_, _, _ = breaker.ValidateAccessToken(context.Background(), "token", "req")
+	if next.calls != 2 {
+		t.Fatalf("expected a probe after the open timeout, got %d calls", next.calls)
+	}
+	if _, _, err := breaker.ValidateAccessToken(context.Background(), "token", "req"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected circuit to re-open after a failed probe, got %v", err)
+	}
+
+	clock.now = clock.now.Add(10 * time.Second)
+	next.err = nil
+	next.identity = Identity{UserID: "user-123"}
+	if userID, _, err := breaker.ValidateAccessToken(context.Background(), "token", "req"); err != nil || userID != "user-123" {
+		t.Fatalf("expected successful probe, got %q, %v", userID, err)
+	}
+	if _, _, err := breaker.ValidateAccessToken(context.Background(), "token", "req"); err != nil {
+		t.Fatalf("expected closed circuit after a successful probe, got %v", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresContractErrors(t *testing.T) {
+	next := &countingValidator{err: &ValidateAccessTokenError{ErrCode: "AUTH_INVALID_TOKEN"}}
+	breaker := NewCircuitBreakingValidator(next, CircuitBreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := breaker.ValidateAccessToken(context.Background(), "token", "req"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: invalid tokens must not open the circuit", i)
+		}
+	}
+	if next.calls != 3 {
+		t.Fatalf("expected every call to reach the user service, got %d", next.calls)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	next := &countingValidator{err: status.Error(codes.Unavailable, "down")}
+	breaker := NewCircuitBreakingValidator(next, CircuitBreakerOptions{})
+
+	for i := 0; i < 5; i++ {
+		_, _, _ = breaker.ValidateAccessToken(context.Background(), "token", "req")
+	}
+	if next.calls != 5 {
+		t.Fatalf("expected a disabled breaker to pass every call through, got %d", next.calls)
+	}
+}
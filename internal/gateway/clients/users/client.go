@@ -2,15 +2,20 @@ package users
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"maps"
+	"os"
 	"strings"
 	"time"
 
 	commonv1 "github.com/ozankenangungor/go-commerce/api/gen/go/common/v1"
 	usersv1 "github.com/ozankenangungor/go-commerce/api/gen/go/users/v1"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Client wraps users.v1 gRPC calls used by the API gateway.
@@ -23,6 +28,8 @@ type Client struct {
 type ValidateAccessTokenError struct {
 	ErrCode    string
 	ErrMessage string
+	Retryable  bool
+	Details    map[string]string
 }
 
 func (e *ValidateAccessTokenError) Error() string {
@@ -43,28 +50,42 @@ func (e *ValidateAccessTokenError) Code() string {
 	return e.ErrCode
 }
 
-// NewClient creates a users service gRPC client for local development.
-func NewClient(ctx context.Context, addr string, dialTimeout time.Duration) (*Client, error) {
+// LoadTLSConfig builds a client TLS config trusting the PEM CA bundle at caFile,
+// or the system roots when caFile is empty.
+func LoadTLSConfig(caFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if strings.TrimSpace(caFile) == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read users grpc CA file %q: %w", caFile, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("users grpc CA file %q contains no PEM certificates", caFile)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// NewClient creates a users service gRPC client.
+func NewClient(ctx context.Context, cfg DialConfig) (*Client, error) {
 	if ctx == nil {
 		return nil, fmt.Errorf("dial context is required")
 	}
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("dial context: %w", err)
 	}
-	if strings.TrimSpace(addr) == "" {
-		return nil, fmt.Errorf("users grpc address is required")
-	}
-	if dialTimeout <= 0 {
-		return nil, fmt.Errorf("grpc dial timeout must be > 0")
+
+	opts, err := dialOptionsFromConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	conn, err := grpc.NewClient(
-		addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithConnectParams(grpc.ConnectParams{
-			MinConnectTimeout: dialTimeout,
-		}),
-	)
+	conn, err := grpc.NewClient(cfg.Addr, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("dial user service grpc: %w", err)
 	}
@@ -83,35 +104,259 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
+// Identity is the verified identity resolved from an access token.
+type Identity struct {
+	UserID    string
+	Roles     []string
+	Scopes    []string
+	ExpiresAt time.Time
+	JTI       string
+}
+
 // ValidateAccessToken validates a bearer token via users.v1.UserService.
 func (c *Client) ValidateAccessToken(ctx context.Context, accessToken string, requestID string) (string, []string, error) {
+	identity, err := c.ValidateAccessTokenIdentity(ctx, accessToken, requestID)
+	if err != nil {
+		return "", nil, err
+	}
+	return identity.UserID, identity.Roles, nil
+}
+
+// ValidateAccessTokenIdentity validates a bearer token and returns the full identity envelope.
+func (c *Client) ValidateAccessTokenIdentity(ctx context.Context, accessToken string, requestID string) (Identity, error) {
 	if c == nil || c.client == nil {
-		return "", nil, errors.New("users grpc client is not initialized")
+		return Identity{}, errors.New("users grpc client is not initialized")
 	}
 	if strings.TrimSpace(accessToken) == "" {
-		return "", nil, errors.New("access token is required")
+		return Identity{}, errors.New("access token is required")
 	}
 
 	resp, err := c.client.ValidateAccessToken(ctx, &usersv1.ValidateAccessTokenRequest{
-		Ctx: &commonv1.RequestContext{
-			RequestId: requestID,
-		},
+		Ctx:         requestContext(ctx, requestID),
 		AccessToken: accessToken,
 	})
 	if err != nil {
-		return "", nil, fmt.Errorf("validate access token rpc: %w", err)
+		return Identity{}, fmt.Errorf("validate access token rpc: %w", err)
 	}
 	if resp == nil {
-		return "", nil, errors.New("validate access token rpc returned nil response")
+		return Identity{}, errors.New("validate access token rpc returned nil response")
 	}
 
 	if resp.GetError() != nil && resp.GetError().GetCode() != "" {
-		return "", nil, &ValidateAccessTokenError{
+		return Identity{}, &ValidateAccessTokenError{
 			ErrCode:    resp.GetError().GetCode(),
 			ErrMessage: resp.GetError().GetMessage(),
+			Retryable:  resp.GetError().GetRetryable(),
+			Details:    maps.Clone(resp.GetError().GetDetails()),
 		}
 	}
 
-	roles := append([]string(nil), resp.GetRoles()...)
-	return resp.GetUserId(), roles, nil
+	identity := resp.GetIdentity()
+	if identity == nil {
+		// Older user service builds only populate the top-level fields.
+		return Identity{
+			UserID: resp.GetUserId(),
+			Roles:  append([]string(nil), resp.GetRoles()...),
+		}, nil
+	}
+
+	result := Identity{
+		UserID: identity.GetUserId(),
+		Roles:  append([]string(nil), identity.GetRoles()...),
+		Scopes: append([]string(nil), identity.GetScopes()...),
+		JTI:    identity.GetJti(),
+	}
+	if identity.GetExpiresAt() != nil {
+		result.ExpiresAt = identity.GetExpiresAt().AsTime()
+	}
+	return result, nil
+}
+
+// ContractError is a contract-level error envelope returned by a user service RPC.
+type ContractError struct {
+	Op         string
+	ErrCode    string
+	ErrMessage string
+
+	// Retryable reports the user service's hint that the same request may succeed later;
+	// Details may carry context such as retry_after (seconds).
+	Retryable bool
+	Details   map[string]string
+}
+
+func (e *ContractError) Error() string {
+	if e == nil {
+		return "user service request failed"
+	}
+	if e.ErrMessage == "" {
+		return fmt.Sprintf("user service %s failed: %s", e.Op, e.ErrCode)
+	}
+	return fmt.Sprintf("user service %s failed: %s (%s)", e.Op, e.ErrCode, e.ErrMessage)
+}
+
+// Code returns the stable contract error code.
+func (e *ContractError) Code() string {
+	if e == nil {
+		return ""
+	}
+	return e.ErrCode
+}
+
+// User is the public user profile returned by the user service.
+type User struct {
+	UserID    string
+	Email     string
+	Name      string
+	CreatedAt time.Time
+}
+
+// Tokens is an issued access/refresh token pair.
+type Tokens struct {
+	AccessToken             string
+	RefreshToken            string
+	AccessExpiresInSeconds  int64
+	RefreshExpiresInSeconds int64
+}
+
+// AuthResult is the outcome of a successful Register or Login call.
+type AuthResult struct {
+	User   User
+	Tokens Tokens
+}
+
+// Register creates an account via users.v1.UserService and returns the new user with tokens.
+func (c *Client) Register(ctx context.Context, email, password, name, requestID string) (AuthResult, error) {
+	if c == nil || c.client == nil {
+		return AuthResult{}, errors.New("users grpc client is not initialized")
+	}
+
+	resp, err := c.client.Register(ctx, &usersv1.RegisterRequest{
+		Ctx:      requestContext(ctx, requestID),
+		Email:    email,
+		Password: password,
+		Name:     name,
+	})
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("register rpc: %w", err)
+	}
+	if resp == nil {
+		return AuthResult{}, errors.New("register rpc returned nil response")
+	}
+	if err := contractError("register", resp.GetError()); err != nil {
+		return AuthResult{}, err
+	}
+	return authResult(resp.GetUser(), resp.GetTokens()), nil
+}
+
+// Login authenticates credentials via users.v1.UserService and returns the user with tokens.
+func (c *Client) Login(ctx context.Context, email, password, requestID string) (AuthResult, error) {
+	if c == nil || c.client == nil {
+		return AuthResult{}, errors.New("users grpc client is not initialized")
+	}
+
+	resp, err := c.client.Login(ctx, &usersv1.LoginRequest{
+		Ctx:      requestContext(ctx, requestID),
+		Email:    email,
+		Password: password,
+	})
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("login rpc: %w", err)
+	}
+	if resp == nil {
+		return AuthResult{}, errors.New("login rpc returned nil response")
+	}
+	if err := contractError("login", resp.GetError()); err != nil {
+		return AuthResult{}, err
+	}
+	return authResult(resp.GetUser(), resp.GetTokens()), nil
+}
+
+func contractError(op string, envelope *commonv1.Error) error {
+	if envelope == nil || envelope.GetCode() == "" {
+		return nil
+	}
+	return &ContractError{
+		Op:         op,
+		ErrCode:    envelope.GetCode(),
+		ErrMessage: envelope.GetMessage(),
+		Retryable:  envelope.GetRetryable(),
+		Details:    maps.Clone(envelope.GetDetails()),
+	}
+}
+
+func authResult(user *usersv1.User, tokens *usersv1.AuthTokens) AuthResult {
+	result := AuthResult{
+		User: User{
+			UserID: user.GetUserId(),
+			Email:  user.GetEmail(),
+			Name:   user.GetName(),
+		},
+		Tokens: Tokens{
+			AccessToken:             tokens.GetAccessToken(),
+			RefreshToken:            tokens.GetRefreshToken(),
+			AccessExpiresInSeconds:  tokens.GetAccessExpiresInSeconds(),
+			RefreshExpiresInSeconds: tokens.GetRefreshExpiresInSeconds(),
+		},
+	}
+	if user.GetCreatedAt() != nil {
+		result.User.CreatedAt = user.GetCreatedAt().AsTime()
+	}
+	return result
+}
+
+// RefreshToken exchanges a refresh token for a new token pair via users.v1.UserService.
+func (c *Client) RefreshToken(ctx context.Context, refreshToken, requestID string) (Tokens, error) {
+	if c == nil || c.client == nil {
+		return Tokens{}, errors.New("users grpc client is not initialized")
+	}
+	if strings.TrimSpace(refreshToken) == "" {
+		return Tokens{}, errors.New("refresh token is required")
+	}
+
+	resp, err := c.client.RefreshToken(ctx, &usersv1.RefreshTokenRequest{
+		Ctx:          requestContext(ctx, requestID),
+		RefreshToken: refreshToken,
+	})
+	if err != nil {
+		return Tokens{}, fmt.Errorf("refresh token rpc: %w", err)
+	}
+	if resp == nil {
+		return Tokens{}, errors.New("refresh token rpc returned nil response")
+	}
+	if err := contractError("refresh token", resp.GetError()); err != nil {
+		return Tokens{}, err
+	}
+	return authResult(nil, resp.GetTokens()).Tokens, nil
+}
+
+// CodeUserNotFound is the contract error code for a profile lookup on an unknown user.
+const CodeUserNotFound = "USER_NOT_FOUND"
+
+// GetProfile fetches a user's public profile via users.v1.UserService. Contract errors,
+// including an unknown user, are returned as *ContractError.
+func (c *Client) GetProfile(ctx context.Context, userID string, requestID string) (User, error) {
+	if c == nil || c.client == nil {
+		return User{}, errors.New("users grpc client is not initialized")
+	}
+	if strings.TrimSpace(userID) == "" {
+		return User{}, errors.New("user id is required")
+	}
+
+	resp, err := c.client.GetProfile(ctx, &usersv1.GetProfileRequest{
+		Ctx:    requestContext(ctx, requestID),
+		UserId: userID,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return User{}, &ContractError{Op: "get profile", ErrCode: CodeUserNotFound, ErrMessage: status.Convert(err).Message()}
+		}
+		return User{}, fmt.Errorf("get profile rpc: %w", err)
+	}
+	if resp == nil {
+		return User{}, errors.New("get profile rpc returned nil response")
+	}
+	if err := contractError("get profile", resp.GetError()); err != nil {
+		return User{}, err
+	}
+	return authResult(resp.GetUser(), nil).User, nil
 }
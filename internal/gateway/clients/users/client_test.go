@@ -0,0 +1,431 @@
+package users
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	commonv1 "github.com/ozankenangungor/go-commerce/api/gen/go/common/v1"
+	usersv1 "github.com/ozankenangungor/go-commerce/api/gen/go/users/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeUserService struct {
+	usersv1.UnimplementedUserServiceServer
+
+	validateFunc func(ctx context.Context, req *usersv1.ValidateAccessTokenRequest) (*usersv1.ValidateAccessTokenResponse, error)
+	registerFunc func(ctx context.Context, req *usersv1.RegisterRequest) (*usersv1.RegisterResponse, error)
+	loginFunc    func(ctx context.Context, req *usersv1.LoginRequest) (*usersv1.LoginResponse, error)
+	refreshFunc  func(ctx context.Context, req *usersv1.RefreshTokenRequest) (*usersv1.RefreshTokenResponse, error)
+	profileFunc  func(ctx context.Context, req *usersv1.GetProfileRequest) (*usersv1.GetProfileResponse, error)
+}
+
+func (f *fakeUserService) GetProfile(ctx context.Context, req *usersv1.GetProfileRequest) (*usersv1.GetProfileResponse, error) {
+	return f.profileFunc(ctx, req)
+}
+
+func (f *fakeUserService) RefreshToken(ctx context.Context, req *usersv1.RefreshTokenRequest) (*usersv1.RefreshTokenResponse, error) {
+	return f.refreshFunc(ctx, req)
+}
+
+func (f *fakeUserService) Register(ctx context.Context, req *usersv1.RegisterRequest) (*usersv1.RegisterResponse, error) {
+	return f.registerFunc(ctx, req)
+}
+
+func (f *fakeUserService) Login(ctx context.Context, req *usersv1.LoginRequest) (*usersv1.LoginResponse, error) {
+	return f.loginFunc(ctx, req)
+}
+
+func (f *fakeUserService) ValidateAccessToken(ctx context.Context, req *usersv1.ValidateAccessTokenRequest) (*usersv1.ValidateAccessTokenResponse, error) {
+	return f.validateFunc(ctx, req)
+}
+
+func TestValidateAccessTokenIdentityPopulated(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := newTestClient(t, &fakeUserService{
+		validateFunc: func(ctx context.Context, req *usersv1.ValidateAccessTokenRequest) (*usersv1.ValidateAccessTokenResponse, error) {
+			if req.GetCtx().GetRequestId() != "req-1" {
+				t.Errorf("expected request id req-1, got %q", req.GetCtx().GetRequestId())
+			}
+			return &usersv1.ValidateAccessTokenResponse{
+				Identity: &usersv1.AccessTokenIdentity{
+					UserId:    "user-123",
+					Roles:     []string{"customer"},
+					Scopes:    []string{"orders:read"},
+					ExpiresAt: timestamppb.New(expiresAt),
+					Jti:       "jti-1",
+				},
+			}, nil
+		},
+	})
+
+	identity, err := client.ValidateAccessTokenIdentity(context.Background(), "token", "req-1")
+	if err != nil {
+		t.Fatalf("validate access token: %v", err)
+	}
+
+	if identity.UserID != "user-123" {
+		t.Fatalf("expected user id user-123, got %q", identity.UserID)
+	}
+	if len(identity.Roles) != 1 || identity.Roles[0] != "customer" {
+		t.Fatalf("unexpected roles: %#v", identity.Roles)
+	}
+	if len(identity.Scopes) != 1 || identity.Scopes[0] != "orders:read" {
+		t.Fatalf("unexpected scopes: %#v", identity.Scopes)
+	}
+	if !identity.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("expected expiry %v, got %v", expiresAt, identity.ExpiresAt)
+	}
+	if identity.JTI != "jti-1" {
+		t.Fatalf("expected jti jti-1, got %q", identity.JTI)
+	}
+}
+
+func TestValidateAccessTokenFallsBackToLegacyFields(t *testing.T) {
+	client := newTestClient(t, &fakeUserService{
+		validateFunc: func(ctx context.Context, req *usersv1.ValidateAccessTokenRequest) (*usersv1.ValidateAccessTokenResponse, error) {
+			return &usersv1.ValidateAccessTokenResponse{
+				UserId: "user-123",
+				Roles:  []string{"customer", "premium"},
+			}, nil
+		},
+	})
+
+	userID, roles, err := client.ValidateAccessToken(context.Background(), "token", "req-1")
+	if err != nil {
+		t.Fatalf("validate access token: %v", err)
+	}
+	if userID != "user-123" {
+		t.Fatalf("expected user id user-123, got %q", userID)
+	}
+	if len(roles) != 2 || roles[0] != "customer" || roles[1] != "premium" {
+		t.Fatalf("unexpected roles: %#v", roles)
+	}
+}
+
+func TestValidateAccessTokenErrorEnvelope(t *testing.T) {
+	client := newTestClient(t, &fakeUserService{
+		validateFunc: func(ctx context.Context, req *usersv1.ValidateAccessTokenRequest) (*usersv1.ValidateAccessTokenResponse, error) {
+			return &usersv1.ValidateAccessTokenResponse{
+				Error: &commonv1.Error{
+					Code:    "AUTH_INVALID_TOKEN",
+					Message: "invalid token",
+				},
+			}, nil
+		},
+	})
+
+	identity, err := client.ValidateAccessTokenIdentity(context.Background(), "token", "req-1")
+	if err == nil {
+		t.Fatal("expected error for error envelope")
+	}
+
+	var validateErr *ValidateAccessTokenError
+	if !errors.As(err, &validateErr) {
+		t.Fatalf("expected ValidateAccessTokenError, got %T", err)
+	}
+	if validateErr.Code() != "AUTH_INVALID_TOKEN" {
+		t.Fatalf("expected code AUTH_INVALID_TOKEN, got %q", validateErr.Code())
+	}
+	if identity.UserID != "" {
+		t.Fatalf("expected empty identity on error, got %#v", identity)
+	}
+}
+
+func TestRegisterReturnsUserAndTokens(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := newTestClient(t, &fakeUserService{
+		registerFunc: func(ctx context.Context, req *usersv1.RegisterRequest) (*usersv1.RegisterResponse, error) {
+			if req.GetEmail() != "jane@example.com" || req.GetName() != "Jane" || req.GetCtx().GetRequestId() != "req-1" {
+				t.Errorf("unexpected register request: %v", req)
+			}
+			return &usersv1.RegisterResponse{
+				User: &usersv1.User{
+					UserId:    "user-123",
+					Email:     "jane@example.com",
+					Name:      "Jane",
+					CreatedAt: timestamppb.New(createdAt),
+				},
+				Tokens: &usersv1.AuthTokens{
+					AccessToken:            "access",
+					RefreshToken:           "refresh",
+					AccessExpiresInSeconds: 900,
+				},
+			}, nil
+		},
+	})
+
+	result, err := client.Register(context.Background(), "jane@example.com", "secret", "Jane", "req-1")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if result.User.UserID != "user-123" || !result.User.CreatedAt.Equal(createdAt) {
+		t.Fatalf("unexpected user: %#v", result.User)
+	}
+	if result.Tokens.AccessToken != "access" || result.Tokens.RefreshToken != "refresh" || result.Tokens.AccessExpiresInSeconds != 900 {
+		t.Fatalf("unexpected tokens: %#v", result.Tokens)
+	}
+}
+
+func TestLoginForwardsOrigin(t *testing.T) {
+	var got *commonv1.RequestContext
+	client := newTestClient(t, &fakeUserService{
+		loginFunc: func(ctx context.Context, req *usersv1.LoginRequest) (*usersv1.LoginResponse, error) {
+			got = req.GetCtx()
+			return &usersv1.LoginResponse{}, nil
+		},
+	})
+
+	ctx := WithOrigin(context.Background(), Origin{ClientIP: "203.0.113.7", UserAgent: "shop-app/1.2"})
+	if _, err := client.Login(ctx, "jane@example.com", "secret", "req-1"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if got.GetRequestId() != "req-1" || got.GetClientIp() != "203.0.113.7" || got.GetUserAgent() != "shop-app/1.2" {
+		t.Fatalf("unexpected request context: %v", got)
+	}
+
+	if _, err := client.Login(context.Background(), "jane@example.com", "secret", "req-2"); err != nil {
+		t.Fatalf("login without origin: %v", err)
+	}
+	if got.GetClientIp() != "" || got.GetUserAgent() != "" {
+		t.Fatalf("expected empty origin fields, got %v", got)
+	}
+}
+
+func TestLoginErrorEnvelope(t *testing.T) {
+	client := newTestClient(t, &fakeUserService{
+		loginFunc: func(ctx context.Context, req *usersv1.LoginRequest) (*usersv1.LoginResponse, error) {
+			return &usersv1.LoginResponse{
+				Error: &commonv1.Error{Code: "AUTH_INVALID_CREDENTIALS", Message: "invalid credentials"},
+			}, nil
+		},
+	})
+
+	_, err := client.Login(context.Background(), "jane@example.com", "wrong", "req-1")
+
+	var contractErr *ContractError
+	if !errors.As(err, &contractErr) {
+		t.Fatalf("expected ContractError, got %v", err)
+	}
+	if contractErr.Code() != "AUTH_INVALID_CREDENTIALS" {
+		t.Fatalf("expected code AUTH_INVALID_CREDENTIALS, got %q", contractErr.Code())
+	}
+}
+
+func TestLoginErrorEnvelopeRetryHints(t *testing.T) {
+	client := newTestClient(t, &fakeUserService{
+		loginFunc: func(ctx context.Context, req *usersv1.LoginRequest) (*usersv1.LoginResponse, error) {
+			return &usersv1.LoginResponse{
+				Error: &commonv1.Error{
+					Code:      "AUTH_ACCOUNT_LOCKED",
+					Message:   "too many attempts",
+					Retryable: true,
+					Details:   map[string]string{"retry_after": "30"},
+				},
+			}, nil
+		},
+	})
+
+	_, err := client.Login(context.Background(), "jane@example.com", "wrong", "req-1")
+
+	var contractErr *ContractError
+	if !errors.As(err, &contractErr) {
+		t.Fatalf("expected ContractError, got %v", err)
+	}
+	if !contractErr.Retryable {
+		t.Fatal("expected retryable hint to be carried")
+	}
+	if contractErr.Details["retry_after"] != "30" {
+		t.Fatalf("expected retry_after 30, got %v", contractErr.Details)
+	}
+}
+
+func TestRefreshTokenErrorEnvelopeKeepsCode(t *testing.T) {
+	client := newTestClient(t, &fakeUserService{
+		refreshFunc: func(ctx context.Context, req *usersv1.RefreshTokenRequest) (*usersv1.RefreshTokenResponse, error) {
+			if req.GetRefreshToken() != "refresh-1" {
+				t.Errorf("expected refresh token refresh-1, got %q", req.GetRefreshToken())
+			}
+			return &usersv1.RefreshTokenResponse{
+				Error: &commonv1.Error{Code: "AUTH_INVALID_REFRESH_TOKEN"},
+			}, nil
+		},
+	})
+
+	_, err := client.RefreshToken(context.Background(), "refresh-1", "req-1")
+
+	var coded interface{ Code() string }
+	if !errors.As(err, &coded) {
+		t.Fatalf("expected coded error, got %v", err)
+	}
+	if coded.Code() != "AUTH_INVALID_REFRESH_TOKEN" {
+		t.Fatalf("expected code AUTH_INVALID_REFRESH_TOKEN, got %q", coded.Code())
+	}
+}
+
+func TestGetProfileReturnsUser(t *testing.T) {
+	client := newTestClient(t, &fakeUserService{
+		profileFunc: func(ctx context.Context, req *usersv1.GetProfileRequest) (*usersv1.GetProfileResponse, error) {
+			if req.GetUserId() != "user-123" {
+				t.Errorf("expected user id user-123, got %q", req.GetUserId())
+			}
+			return &usersv1.GetProfileResponse{
+				User: &usersv1.User{UserId: "user-123", Email: "jane@example.com", Name: "Jane"},
+			}, nil
+		},
+	})
+
+	user, err := client.GetProfile(context.Background(), "user-123", "req-1")
+	if err != nil {
+		t.Fatalf("get profile: %v", err)
+	}
+	if user.UserID != "user-123" || user.Email != "jane@example.com" || user.Name != "Jane" {
+		t.Fatalf("unexpected profile: %#v", user)
+	}
+}
+
+func TestGetProfileMapsNotFound(t *testing.T) {
+	client := newTestClient(t, &fakeUserService{
+		profileFunc: func(ctx context.Context, req *usersv1.GetProfileRequest) (*usersv1.GetProfileResponse, error) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		},
+	})
+
+	_, err := client.GetProfile(context.Background(), "user-404", "req-1")
+
+	var contractErr *ContractError
+	if !errors.As(err, &contractErr) {
+		t.Fatalf("expected ContractError, got %v", err)
+	}
+	if contractErr.Code() != CodeUserNotFound {
+		t.Fatalf("expected code %s, got %q", CodeUserNotFound, contractErr.Code())
+	}
+}
+
+func newTestClient(t *testing.T, service usersv1.UserServiceServer) *Client {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	usersv1.RegisterUserServiceServer(server, service)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+
+	client := &Client{
+		conn:   conn,
+		client: usersv1.NewUserServiceClient(conn),
+	}
+	t.Cleanup(func() {
+		if closeErr := client.Close(); closeErr != nil {
+			t.Errorf("close client: %v", closeErr)
+		}
+	})
+
+	return client
+}
+
+func TestLoadTLSConfigUnreadableCAFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing-ca.pem")
+
+	_, err := LoadTLSConfig(missing)
+	if err == nil {
+		t.Fatal("expected error for unreadable CA file")
+	}
+	if !strings.Contains(err.Error(), missing) {
+		t.Fatalf("expected error to name the CA path, got %v", err)
+	}
+}
+
+func TestLoadTLSConfigRejectsNonPEM(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write CA file: %v", err)
+	}
+
+	if _, err := LoadTLSConfig(caFile); err == nil {
+		t.Fatal("expected error for CA file without PEM certificates")
+	}
+}
+
+func TestLoadTLSConfigWithCAFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, newTestCAPEM(t), 0o600); err != nil {
+		t.Fatalf("write CA file: %v", err)
+	}
+
+	tlsConfig, err := LoadTLSConfig(caFile)
+	if err != nil {
+		t.Fatalf("load tls config: %v", err)
+	}
+	if tlsConfig.RootCAs == nil {
+		t.Fatal("expected RootCAs to be populated from CA file")
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("expected min TLS 1.2, got %x", tlsConfig.MinVersion)
+	}
+}
+
+func TestLoadTLSConfigWithoutCAFileUsesSystemRoots(t *testing.T) {
+	tlsConfig, err := LoadTLSConfig("")
+	if err != nil {
+		t.Fatalf("load tls config: %v", err)
+	}
+	if tlsConfig.RootCAs != nil {
+		t.Fatal("expected nil RootCAs so system roots are used")
+	}
+}
+
+func newTestCAPEM(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "go-commerce test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
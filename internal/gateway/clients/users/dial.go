@@ -0,0 +1,124 @@
+package users
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	commonv1 "github.com/ozankenangungor/go-commerce/api/gen/go/common/v1"
+	usersv1 "github.com/ozankenangungor/go-commerce/api/gen/go/users/v1"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+)
+
+const requestIDMetadataKey = "x-request-id"
+
+// DialConfig holds the connection settings for the users gRPC client.
+type DialConfig struct {
+	Addr        string
+	DialTimeout time.Duration
+
+	// TLSConfig enables transport security. Nil dials without it, which is intended for
+	// local development only.
+	TLSConfig *tls.Config
+
+	// KeepaliveTime, when positive, pings idle connections at that interval and drops them
+	// if no ack arrives within KeepaliveTimeout, so dead connections surface as Unavailable.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+
+	// MaxRecvMsgSize caps response sizes in bytes; keep it in line with the user service's
+	// send limit so large batch responses are not rejected. Zero keeps the gRPC default.
+	MaxRecvMsgSize int
+
+	// ValidateMaxAttempts caps attempts, including the first, for ValidateAccessToken when
+	// the user service is Unavailable or the attempt times out. Values below 2 disable
+	// retries. Only that idempotent call is retried; Register, Login and RefreshToken never are.
+	ValidateMaxAttempts int
+}
+
+// maxRetryAttempts is gRPC's own ceiling on retryPolicy.maxAttempts; larger values are
+// silently clamped, so reject them instead.
+const maxRetryAttempts = 5
+
+// validateRetryServiceConfig builds a service config that retries ValidateAccessToken with
+// exponential backoff. gRPC randomizes each backoff, so retries from many gateways spread out.
+func validateRetryServiceConfig(maxAttempts int) string {
+	return fmt.Sprintf(`{"methodConfig":[{"name":[{"service":%q,"method":"ValidateAccessToken"}],`+
+		`"retryPolicy":{"maxAttempts":%d,"initialBackoff":"0.05s","maxBackoff":"0.5s","backoffMultiplier":2,`+
+		`"retryableStatusCodes":["UNAVAILABLE","DEADLINE_EXCEEDED"]}}]}`,
+		usersv1.UserService_ServiceDesc.ServiceName, maxAttempts)
+}
+
+func dialOptionsFromConfig(cfg DialConfig) ([]grpc.DialOption, error) {
+	if strings.TrimSpace(cfg.Addr) == "" {
+		return nil, fmt.Errorf("users grpc address is required")
+	}
+	if cfg.DialTimeout <= 0 {
+		return nil, fmt.Errorf("grpc dial timeout must be > 0")
+	}
+	if cfg.KeepaliveTime < 0 || cfg.KeepaliveTimeout < 0 {
+		return nil, fmt.Errorf("grpc keepalive time and timeout must be >= 0")
+	}
+	if cfg.MaxRecvMsgSize < 0 {
+		return nil, fmt.Errorf("grpc max receive message size must be >= 0")
+	}
+	if cfg.ValidateMaxAttempts > maxRetryAttempts {
+		return nil, fmt.Errorf("grpc validate max attempts must be <= %d", maxRetryAttempts)
+	}
+
+	transportCredentials := insecure.NewCredentials()
+	if cfg.TLSConfig != nil {
+		transportCredentials = credentials.NewTLS(cfg.TLSConfig)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(requestIDMetadataInterceptor),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			MinConnectTimeout: cfg.DialTimeout,
+		}),
+	}
+
+	if cfg.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.KeepaliveTime,
+			Timeout:             cfg.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize)))
+	}
+
+	if cfg.ValidateMaxAttempts > 1 {
+		opts = append(opts, grpc.WithDefaultServiceConfig(validateRetryServiceConfig(cfg.ValidateMaxAttempts)))
+	}
+
+	return opts, nil
+}
+
+// requestContextCarrier is implemented by every users.v1 request message.
+type requestContextCarrier interface {
+	GetCtx() *commonv1.RequestContext
+}
+
+// requestIDMetadataInterceptor mirrors the RequestContext request id into x-request-id
+// metadata so the user service can correlate an RPC before decoding its body. The caller's
+// remaining deadline needs no extra metadata: gRPC already sends it as grpc-timeout.
+func requestIDMetadataInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if carrier, ok := req.(requestContextCarrier); ok {
+		if requestID := carrier.GetCtx().GetRequestId(); requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, requestIDMetadataKey, requestID)
+		}
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
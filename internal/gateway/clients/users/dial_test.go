@@ -0,0 +1,217 @@
+package users
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	commonv1 "github.com/ozankenangungor/go-commerce/api/gen/go/common/v1"
+	usersv1 "github.com/ozankenangungor/go-commerce/api/gen/go/users/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestDialOptionsFromConfigValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  DialConfig
+	}{
+		{name: "missing address", cfg: DialConfig{DialTimeout: time.Second}},
+		{name: "zero dial timeout", cfg: DialConfig{Addr: "users:50051"}},
+		{name: "negative keepalive time", cfg: DialConfig{Addr: "users:50051", DialTimeout: time.Second, KeepaliveTime: -time.Second}},
+		{name: "negative keepalive timeout", cfg: DialConfig{Addr: "users:50051", DialTimeout: time.Second, KeepaliveTimeout: -time.Second}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := dialOptionsFromConfig(tc.cfg); err == nil {
+				t.Fatal("expected config error")
+			}
+		})
+	}
+}
+
+func TestDialOptionsFromConfigKeepalive(t *testing.T) {
+	base := DialConfig{Addr: "users:50051", DialTimeout: time.Second}
+
+	without, err := dialOptionsFromConfig(base)
+	if err != nil {
+		t.Fatalf("dial options without keepalive: %v", err)
+	}
+
+	withKeepalive := base
+	withKeepalive.KeepaliveTime = 30 * time.Second
+	withKeepalive.KeepaliveTimeout = 10 * time.Second
+	with, err := dialOptionsFromConfig(withKeepalive)
+	if err != nil {
+		t.Fatalf("dial options with keepalive: %v", err)
+	}
+
+	if len(with) != len(without)+1 {
+		t.Fatalf("expected keepalive to add one dial option, got %d vs %d", len(with), len(without))
+	}
+}
+
+func TestDialOptionsFromConfigTransportSecurity(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	usersv1.RegisterUserServiceServer(server, &fakeUserService{
+		profileFunc: func(ctx context.Context, req *usersv1.GetProfileRequest) (*usersv1.GetProfileResponse, error) {
+			return &usersv1.GetProfileResponse{}, nil
+		},
+	})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	tests := []struct {
+		name     string
+		tls      *tls.Config
+		wantCode codes.Code
+	}{
+		{name: "plaintext reaches a plaintext server", wantCode: codes.OK},
+		{name: "tls refuses a plaintext server", tls: &tls.Config{MinVersion: tls.VersionTLS12}, wantCode: codes.Unavailable},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, err := dialOptionsFromConfig(DialConfig{Addr: "passthrough:///bufnet", DialTimeout: time.Second, TLSConfig: tc.tls})
+			if err != nil {
+				t.Fatalf("dial options: %v", err)
+			}
+			opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}))
+
+			conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+			defer conn.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			_, err = usersv1.NewUserServiceClient(conn).GetProfile(ctx, &usersv1.GetProfileRequest{})
+			if got := status.Code(err); got != tc.wantCode {
+				t.Fatalf("expected code %v, got %v (%v)", tc.wantCode, got, err)
+			}
+		})
+	}
+}
+
+func TestDialOptionsForwardRequestMetadata(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+
+	var gotRequestID []string
+	var gotDeadline bool
+	usersv1.RegisterUserServiceServer(server, &fakeUserService{
+		profileFunc: func(ctx context.Context, req *usersv1.GetProfileRequest) (*usersv1.GetProfileResponse, error) {
+			gotRequestID = metadata.ValueFromIncomingContext(ctx, "x-request-id")
+			_, gotDeadline = ctx.Deadline()
+			return &usersv1.GetProfileResponse{}, nil
+		},
+	})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	opts, err := dialOptionsFromConfig(DialConfig{Addr: "passthrough:///bufnet", DialTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("dial options: %v", err)
+	}
+	opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = usersv1.NewUserServiceClient(conn).GetProfile(ctx, &usersv1.GetProfileRequest{
+		Ctx: &commonv1.RequestContext{RequestId: "req-123"},
+	})
+	if err != nil {
+		t.Fatalf("get profile: %v", err)
+	}
+
+	if len(gotRequestID) != 1 || gotRequestID[0] != "req-123" {
+		t.Fatalf("expected x-request-id req-123, got %v", gotRequestID)
+	}
+	if !gotDeadline {
+		t.Fatal("expected the caller deadline to reach the server")
+	}
+}
+
+func TestDialOptionsRetryOnlyValidateAccessToken(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+
+	var validateCalls, loginCalls atomic.Int32
+	usersv1.RegisterUserServiceServer(server, &fakeUserService{
+		validateFunc: func(ctx context.Context, req *usersv1.ValidateAccessTokenRequest) (*usersv1.ValidateAccessTokenResponse, error) {
+			if validateCalls.Add(1) == 1 {
+				return nil, status.Error(codes.Unavailable, "rolling restart")
+			}
+			return &usersv1.ValidateAccessTokenResponse{UserId: "user-123"}, nil
+		},
+		loginFunc: func(ctx context.Context, req *usersv1.LoginRequest) (*usersv1.LoginResponse, error) {
+			loginCalls.Add(1)
+			return nil, status.Error(codes.Unavailable, "rolling restart")
+		},
+	})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	opts, err := dialOptionsFromConfig(DialConfig{Addr: "passthrough:///bufnet", DialTimeout: time.Second, ValidateMaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("dial options: %v", err)
+	}
+	opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer conn.Close()
+	client := &Client{conn: conn, client: usersv1.NewUserServiceClient(conn)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	userID, _, err := client.ValidateAccessToken(ctx, "token", "req-1")
+	if err != nil {
+		t.Fatalf("expected validate to succeed after a retry, got %v", err)
+	}
+	if userID != "user-123" || validateCalls.Load() != 2 {
+		t.Fatalf("expected user-123 after 2 attempts, got %q after %d", userID, validateCalls.Load())
+	}
+
+	if _, err := client.Login(ctx, "a@example.com", "secret", "req-2"); status.Code(errors.Unwrap(err)) != codes.Unavailable {
+		t.Fatalf("expected login to fail with Unavailable, got %v", err)
+	}
+	if loginCalls.Load() != 1 {
+		t.Fatalf("expected login not to be retried, got %d attempts", loginCalls.Load())
+	}
+}
+
+func TestDialOptionsRejectsTooManyAttempts(t *testing.T) {
+	if _, err := dialOptionsFromConfig(DialConfig{Addr: "users:50051", DialTimeout: time.Second, ValidateMaxAttempts: 6}); err == nil {
+		t.Fatal("expected error for more than 5 attempts")
+	}
+}
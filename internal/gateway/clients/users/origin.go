@@ -0,0 +1,38 @@
+package users
+
+import (
+	"context"
+
+	commonv1 "github.com/ozankenangungor/go-commerce/api/gen/go/common/v1"
+)
+
+// Origin describes the end user behind a gateway request, for auditing in the user service.
+type Origin struct {
+	ClientIP  string
+	UserAgent string
+}
+
+type originContextKey struct{}
+
+// WithOrigin attaches the end user's origin to ctx; client calls made with the returned
+// context forward it in the RequestContext.
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originContextKey{}, origin)
+}
+
+// OriginFromContext returns the origin attached by WithOrigin.
+func OriginFromContext(ctx context.Context) (Origin, bool) {
+	origin, ok := ctx.Value(originContextKey{}).(Origin)
+	return origin, ok
+}
+
+// requestContext builds the RequestContext for an RPC. Origin fields stay empty when ctx
+// carries none.
+func requestContext(ctx context.Context, requestID string) *commonv1.RequestContext {
+	origin, _ := OriginFromContext(ctx)
+	return &commonv1.RequestContext{
+		RequestId: requestID,
+		ClientIp:  origin.ClientIP,
+		UserAgent: origin.UserAgent,
+	}
+}
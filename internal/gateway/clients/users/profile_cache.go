@@ -0,0 +1,62 @@
+package users
+
+import (
+	"context"
+	"time"
+)
+
+// ProfileCacheOptions configures CachingProfileFetcher.
+type ProfileCacheOptions struct {
+	// Size is the maximum number of cached profiles.
+	Size int
+
+	// TTL is how long a fetched profile is reused. Keep it short: profile edits are not
+	// visible through the cache until the entry expires.
+	TTL time.Duration
+}
+
+type profileFetcher interface {
+	GetProfile(ctx context.Context, userID string, requestID string) (User, error)
+}
+
+// CachingProfileFetcher caches successful GetProfile results per user id.
+type CachingProfileFetcher struct {
+	next  profileFetcher
+	ttl   time.Duration
+	now   func() time.Time
+	cache *ttlCache[string, User]
+}
+
+// NewCachingProfileFetcher wraps next with a short-TTL profile cache.
+func NewCachingProfileFetcher(next profileFetcher, opts ProfileCacheOptions) *CachingProfileFetcher {
+	return newCachingProfileFetcher(next, opts, time.Now)
+}
+
+func newCachingProfileFetcher(next profileFetcher, opts ProfileCacheOptions, now func() time.Time) *CachingProfileFetcher {
+	size := opts.Size
+	if opts.TTL <= 0 {
+		size = 0
+	}
+
+	return &CachingProfileFetcher{
+		next:  next,
+		ttl:   opts.TTL,
+		now:   now,
+		cache: newTTLCache[string, User](size, now),
+	}
+}
+
+// GetProfile returns a cached profile when fresh, and otherwise fetches and caches it.
+func (f *CachingProfileFetcher) GetProfile(ctx context.Context, userID string, requestID string) (User, error) {
+	if user, ok := f.cache.get(userID); ok {
+		return user, nil
+	}
+
+	user, err := f.next.GetProfile(ctx, userID, requestID)
+	if err != nil {
+		return User{}, err
+	}
+
+	f.cache.set(userID, user, f.now().Add(f.ttl))
+	return user, nil
+}
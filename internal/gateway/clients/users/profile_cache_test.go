@@ -0,0 +1,43 @@
+package users
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type countingProfileFetcher struct {
+	calls int
+}
+
+func (c *countingProfileFetcher) GetProfile(ctx context.Context, userID string, requestID string) (User, error) {
+	c.calls++
+	return User{UserID: userID, Name: "Jane"}, nil
+}
+
+func TestCachingProfileFetcherCacheHit(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	next := &countingProfileFetcher{}
+	fetcher := newCachingProfileFetcher(next, ProfileCacheOptions{Size: 10, TTL: 5 * time.Second}, clock.Now)
+
+	for range 2 {
+		user, err := fetcher.GetProfile(context.Background(), "user-123", "req-1")
+		if err != nil {
+			t.Fatalf("get profile: %v", err)
+		}
+		if user.UserID != "user-123" {
+			t.Fatalf("unexpected profile: %#v", user)
+		}
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected cache hit on second call, got %d rpcs", next.calls)
+	}
+
+	clock.now = clock.now.Add(5 * time.Second)
+	if _, err := fetcher.GetProfile(context.Background(), "user-123", "req-2"); err != nil {
+		t.Fatalf("get profile: %v", err)
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected refetch after TTL, got %d rpcs", next.calls)
+	}
+}
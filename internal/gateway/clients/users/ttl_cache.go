@@ -0,0 +1,84 @@
+package users
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type ttlCacheEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache is a size-bounded LRU cache whose entries also expire at a per-entry deadline.
+type ttlCache[K comparable, V any] struct {
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[K]*list.Element
+}
+
+func newTTLCache[K comparable, V any](size int, now func() time.Time) *ttlCache[K, V] {
+	return &ttlCache[K, V]{
+		size:    size,
+		now:     now,
+		order:   list.New(),
+		entries: make(map[K]*list.Element),
+	}
+}
+
+func (c *ttlCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := element.Value.(*ttlCacheEntry[K, V])
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// set stores value until expiresAt, evicting the least recently used entry when full.
+// Entries that are already expired are not stored.
+func (c *ttlCache[K, V]) set(key K, value V, expiresAt time.Time) {
+	if c.size <= 0 || !c.now().Before(expiresAt) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &ttlCacheEntry[K, V]{key: key, value: value, expiresAt: expiresAt}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*ttlCacheEntry[K, V]).key)
+	}
+}
+
+func (c *ttlCache[K, V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,97 @@
+package users
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+)
+
+// ValidationCacheOptions configures CachingValidator.
+type ValidationCacheOptions struct {
+	// Size is the maximum number of cached validations; the least recently used entry is
+	// evicted when it is exceeded.
+	Size int
+
+	// MaxTTL caps how long a validation is reused, bounding how long a revoked session can
+	// keep passing. Entries never outlive the token's own expiry.
+	MaxTTL time.Duration
+}
+
+type identityValidator interface {
+	ValidateAccessTokenIdentity(ctx context.Context, accessToken string, requestID string) (Identity, error)
+}
+
+type freshValidationContextKey struct{}
+
+// WithFreshValidation marks ctx so CachingValidator skips its cache read for this call. The
+// fresh result still repopulates the cache.
+func WithFreshValidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshValidationContextKey{}, true)
+}
+
+func freshValidationRequested(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshValidationContextKey{}).(bool)
+	return fresh
+}
+
+type cachedValidation struct {
+	userID string
+	roles  []string
+}
+
+// CachingValidator caches successful token validations in memory, keyed by the token's
+// SHA-256 hash, so repeat requests with the same token skip the ValidateAccessToken RPC.
+// Failed validations are never cached.
+type CachingValidator struct {
+	next   identityValidator
+	maxTTL time.Duration
+	now    func() time.Time
+	cache  *ttlCache[[sha256.Size]byte, cachedValidation]
+}
+
+// NewCachingValidator wraps next with an LRU validation cache.
+func NewCachingValidator(next identityValidator, opts ValidationCacheOptions) *CachingValidator {
+	return newCachingValidator(next, opts, time.Now)
+}
+
+func newCachingValidator(next identityValidator, opts ValidationCacheOptions, now func() time.Time) *CachingValidator {
+	size := opts.Size
+	if opts.MaxTTL <= 0 {
+		size = 0
+	}
+
+	return &CachingValidator{
+		next:   next,
+		maxTTL: opts.MaxTTL,
+		now:    now,
+		cache:  newTTLCache[[sha256.Size]byte, cachedValidation](size, now),
+	}
+}
+
+// ValidateAccessToken returns a cached validation when one is still fresh (unless ctx was
+// marked with WithFreshValidation), and otherwise calls through to the user service and
+// caches the result.
+func (v *CachingValidator) ValidateAccessToken(ctx context.Context, accessToken string, requestID string) (string, []string, error) {
+	key := sha256.Sum256([]byte(accessToken))
+	if !freshValidationRequested(ctx) {
+		if cached, ok := v.cache.get(key); ok {
+			return cached.userID, append([]string(nil), cached.roles...), nil
+		}
+	}
+
+	identity, err := v.next.ValidateAccessTokenIdentity(ctx, accessToken, requestID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	expiresAt := v.now().Add(v.maxTTL)
+	if !identity.ExpiresAt.IsZero() && identity.ExpiresAt.Before(expiresAt) {
+		expiresAt = identity.ExpiresAt
+	}
+	v.cache.set(key, cachedValidation{
+		userID: identity.UserID,
+		roles:  append([]string(nil), identity.Roles...),
+	}, expiresAt)
+
+	return identity.UserID, append([]string(nil), identity.Roles...), nil
+}
@@ -0,0 +1,127 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type countingValidator struct {
+	calls    int
+	identity Identity
+	err      error
+}
+
+func (c *countingValidator) ValidateAccessTokenIdentity(ctx context.Context, accessToken string, requestID string) (Identity, error) {
+	c.calls++
+	return c.identity, c.err
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestCachingValidatorReusesValidationUntilTokenExpiry(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	next := &countingValidator{identity: Identity{
+		UserID:    "user-123",
+		Roles:     []string{"customer"},
+		ExpiresAt: clock.now.Add(10 * time.Second),
+	}}
+	validator := newCachingValidator(next, ValidationCacheOptions{Size: 10, MaxTTL: time.Minute}, clock.Now)
+
+	for range 3 {
+		userID, roles, err := validator.ValidateAccessToken(context.Background(), "token", "req-1")
+		if err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+		if userID != "user-123" || len(roles) != 1 || roles[0] != "customer" {
+			t.Fatalf("unexpected validation result: %q %v", userID, roles)
+		}
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected 1 rpc for repeated token, got %d", next.calls)
+	}
+
+	// The token's own expiry is sooner than MaxTTL, so it bounds the cache entry.
+	clock.now = clock.now.Add(10 * time.Second)
+	if _, _, err := validator.ValidateAccessToken(context.Background(), "token", "req-2"); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected expired entry to call through, got %d calls", next.calls)
+	}
+}
+
+func TestCachingValidatorMaxTTLBoundsRevokedSessions(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	next := &countingValidator{identity: Identity{
+		UserID:    "user-123",
+		ExpiresAt: clock.now.Add(time.Hour),
+	}}
+	validator := newCachingValidator(next, ValidationCacheOptions{Size: 10, MaxTTL: 30 * time.Second}, clock.Now)
+
+	if _, _, err := validator.ValidateAccessToken(context.Background(), "token", "req-1"); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	// The session is revoked: the user service now rejects the token.
+	next.err = &ValidateAccessTokenError{ErrCode: "AUTH_INVALID_TOKEN"}
+	clock.now = clock.now.Add(30 * time.Second)
+
+	_, _, err := validator.ValidateAccessToken(context.Background(), "token", "req-2")
+	var validateErr *ValidateAccessTokenError
+	if !errors.As(err, &validateErr) {
+		t.Fatalf("expected revoked token to fail once MaxTTL elapsed, got %v", err)
+	}
+}
+
+func TestCachingValidatorDoesNotCacheFailures(t *testing.T) {
+	next := &countingValidator{err: errors.New("unavailable")}
+	validator := NewCachingValidator(next, ValidationCacheOptions{Size: 10, MaxTTL: time.Minute})
+
+	for range 2 {
+		if _, _, err := validator.ValidateAccessToken(context.Background(), "token", "req-1"); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected failures to call through every time, got %d calls", next.calls)
+	}
+}
+
+func TestCachingValidatorEvictsLeastRecentlyUsed(t *testing.T) {
+	next := &countingValidator{identity: Identity{UserID: "user-123"}}
+	validator := NewCachingValidator(next, ValidationCacheOptions{Size: 2, MaxTTL: time.Minute})
+
+	for _, token := range []string{"a", "b", "a", "c"} {
+		if _, _, err := validator.ValidateAccessToken(context.Background(), token, "req-1"); err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+	}
+	if validator.cache.len() != 2 {
+		t.Fatalf("expected cache capped at 2 entries, got %d", validator.cache.len())
+	}
+
+	// "b" was least recently used when "c" arrived, so only it needs a new RPC.
+	calls := next.calls
+	for _, token := range []string{"a", "c"} {
+		if _, _, err := validator.ValidateAccessToken(context.Background(), token, "req-1"); err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+	}
+	if next.calls != calls {
+		t.Fatalf("expected a and c to be cached, got %d extra calls", next.calls-calls)
+	}
+	if _, _, err := validator.ValidateAccessToken(context.Background(), "b", "req-1"); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if next.calls != calls+1 {
+		t.Fatal("expected evicted token b to call through")
+	}
+}
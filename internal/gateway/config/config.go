@@ -2,43 +2,278 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"net/netip"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/ozankenangungor/go-commerce/pkg/configfile"
 )
 
 const (
-	defaultGatewayHTTPAddr     = ":8080"
-	defaultUserServiceGRPCAddr = "localhost:50051"
-	defaultGRPCDialTimeout     = 3 * time.Second
-	defaultAuthRPCTimeout      = 2 * time.Second
-	defaultLogLevel            = "info"
+	defaultGatewayHTTPAddr      = ":8080"
+	defaultUserServiceGRPCAddr  = "localhost:50051"
+	defaultGRPCDialTimeout      = 3 * time.Second
+	defaultGRPCMaxRecvMsgSize   = 4 * 1024 * 1024
+	defaultGRPCKeepaliveTime    = 30 * time.Second
+	defaultGRPCKeepaliveTimeout = 10 * time.Second
+	defaultGRPCValidateAttempts = 3
+	defaultAuthRPCTimeout       = 2 * time.Second
+	defaultRequestTimeout       = 5 * time.Second
+	defaultLogLevel             = "info"
+	defaultLogFormat            = "json"
+	defaultAccessLogFormat      = "json"
+	defaultAccessLogSlow        = time.Second
+	defaultErrorFormat          = "json"
+	defaultCORSAllowedMethods   = "GET,POST,PUT,PATCH,DELETE,OPTIONS"
+	defaultCORSAllowedHeaders   = "Authorization,Content-Type,X-Request-ID"
+	defaultCORSMaxAge           = 10 * time.Minute
+	defaultRateLimitRPS         = 10
+	defaultRateLimitBurst       = 20
+	defaultAuthCacheSize        = 10000
+	defaultAuthCacheMaxTTL      = 30 * time.Second
+	defaultProfileCacheSize     = 1000
+	defaultProfileCacheTTL      = 5 * time.Second
+	defaultAuthBreakerFailures  = 5
+	defaultAuthBreakerOpen      = 10 * time.Second
 )
 
 // Config contains runtime configuration for the API gateway.
 type Config struct {
-	GatewayHTTPAddr     string
-	UserServiceGRPCAddr string
-	GRPCDialTimeout     time.Duration
-	AuthRPCTimeout      time.Duration
-	LogLevel            string
+	GatewayHTTPAddr        string
+	UserServiceGRPCAddr    string
+	UserServiceTLSEnabled  bool
+	UserServiceTLSCAFile   string
+	GRPCDialTimeout        time.Duration
+	GRPCMaxRecvMsgSize     int
+	GRPCKeepaliveTime      time.Duration
+	GRPCKeepaliveTimeout   time.Duration
+	GRPCValidateAttempts   int
+	AuthRPCTimeout         time.Duration
+	RequestTimeout         time.Duration
+	LogLevel               string
+	LogFormat              string
+	AccessLogFormat        string
+	ErrorFormat            string
+	AccessLogClientIP      bool
+	AccessLogSampleRate    float64
+	AccessLogSlowThreshold time.Duration
+	MeIncludeProfile       bool
+	RequestIDSuffix        bool
+	InternalTrustedCIDRs   []netip.Prefix
+	CORSAllowedOrigins     []string
+	CORSAllowedMethods     []string
+	CORSAllowedHeaders     []string
+	CORSAllowCredentials   bool
+	CORSMaxAge             time.Duration
+	RateLimitRPS           float64
+	RateLimitBurst         int
+	RateLimitHeaders       bool
+	TrustedProxyHops       int
+	ShutdownDrainDelay     time.Duration
+	ShutdownRejectNew      bool
+	HSTSMaxAge             time.Duration
+	HSTSIncludeSubdomains  bool
+	AuthCacheSize          int
+	AuthCacheMaxTTL        time.Duration
+	ProfileCacheSize       int
+	ProfileCacheTTL        time.Duration
+	AuthBreakerFailures    int
+	AuthBreakerOpenTime    time.Duration
+	OTLPEndpoint           string
+	OTLPInsecure           bool
 }
 
-// Load reads configuration from environment variables with sensible defaults.
+// Load reads configuration from environment variables with sensible defaults. Variables
+// that are unset fall back to the optional CONFIG_FILE; validation runs on the merged values.
 func Load() (Config, error) {
+	src, err := configfile.FromEnv()
+	if err != nil {
+		return Config{}, err
+	}
+
 	cfg := Config{
-		GatewayHTTPAddr:     getEnv("GATEWAY_HTTP_ADDR", defaultGatewayHTTPAddr),
-		UserServiceGRPCAddr: getEnv("USER_SERVICE_GRPC_ADDR", defaultUserServiceGRPCAddr),
-		LogLevel:            strings.TrimSpace(getEnv("LOG_LEVEL", defaultLogLevel)),
+		GatewayHTTPAddr:      getEnv(src, "GATEWAY_HTTP_ADDR", defaultGatewayHTTPAddr),
+		UserServiceGRPCAddr:  getEnv(src, "USER_SERVICE_GRPC_ADDR", defaultUserServiceGRPCAddr),
+		UserServiceTLSCAFile: getEnv(src, "USER_SERVICE_TLS_CA_FILE", ""),
+		LogLevel:             strings.TrimSpace(getEnv(src, "LOG_LEVEL", defaultLogLevel)),
+		LogFormat:            strings.ToLower(getEnv(src, "LOG_FORMAT", defaultLogFormat)),
+		AccessLogFormat:      strings.ToLower(getEnv(src, "ACCESS_LOG_FORMAT", defaultAccessLogFormat)),
+		ErrorFormat:          strings.ToLower(getEnv(src, "ERROR_FORMAT", defaultErrorFormat)),
+		CORSAllowedOrigins:   getListEnv(src, "CORS_ALLOWED_ORIGINS", ""),
+		CORSAllowedMethods:   getListEnv(src, "CORS_ALLOWED_METHODS", defaultCORSAllowedMethods),
+		CORSAllowedHeaders:   getListEnv(src, "CORS_ALLOWED_HEADERS", defaultCORSAllowedHeaders),
+		OTLPEndpoint:         getEnv(src, "OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+	}
+
+	cfg.GRPCDialTimeout, err = getDurationEnv(src, "GRPC_DIAL_TIMEOUT", defaultGRPCDialTimeout)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// Should match USER_GRPC_MAX_SEND_MSG_SIZE on the user service for large responses.
+	cfg.GRPCMaxRecvMsgSize, err = getIntEnv(src, "GRPC_MAX_RECV_MSG_SIZE", defaultGRPCMaxRecvMsgSize)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// GRPC_KEEPALIVE_TIME must not be below the user service's USER_GRPC_KEEPALIVE_MIN_TIME.
+	cfg.GRPCKeepaliveTime, err = getDurationEnv(src, "GRPC_KEEPALIVE_TIME", defaultGRPCKeepaliveTime)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.GRPCKeepaliveTimeout, err = getDurationEnv(src, "GRPC_KEEPALIVE_TIMEOUT", defaultGRPCKeepaliveTimeout)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.GRPCValidateAttempts, err = getIntEnv(src, "GRPC_VALIDATE_MAX_ATTEMPTS", defaultGRPCValidateAttempts)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.AuthRPCTimeout, err = getDurationEnv(src, "AUTH_RPC_TIMEOUT", defaultAuthRPCTimeout)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.RequestTimeout, err = getDurationEnv(src, "REQUEST_TIMEOUT", defaultRequestTimeout)
+	if err != nil {
+		return Config{}, err
 	}
 
-	var err error
-	cfg.GRPCDialTimeout, err = getDurationEnv("GRPC_DIAL_TIMEOUT", defaultGRPCDialTimeout)
+	// Configuring a CA bundle implies TLS.
+	cfg.UserServiceTLSEnabled, err = getBoolEnv(src, "USER_SERVICE_TLS_ENABLED", cfg.UserServiceTLSCAFile != "")
 	if err != nil {
 		return Config{}, err
 	}
+	if cfg.UserServiceTLSCAFile != "" && !cfg.UserServiceTLSEnabled {
+		return Config{}, fmt.Errorf("USER_SERVICE_TLS_CA_FILE requires USER_SERVICE_TLS_ENABLED")
+	}
 
-	cfg.AuthRPCTimeout, err = getDurationEnv("AUTH_RPC_TIMEOUT", defaultAuthRPCTimeout)
+	cfg.RequestIDSuffix, err = getBoolEnv(src, "REQUEST_ID_SUFFIX", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.AccessLogClientIP, err = getBoolEnv(src, "ACCESS_LOG_CLIENT_IP", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.AccessLogSampleRate, err = getFloatEnv(src, "ACCESS_LOG_SAMPLE_RATE", 1)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.AccessLogSlowThreshold, err = getDurationEnv(src, "ACCESS_LOG_SLOW_THRESHOLD", defaultAccessLogSlow)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// Off by default: the profile costs an extra RPC and DB read per /v1/me call.
+	cfg.MeIncludeProfile, err = getBoolEnv(src, "ME_INCLUDE_PROFILE", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.InternalTrustedCIDRs, err = getPrefixListEnv(src, "INTERNAL_TRUSTED_CIDRS")
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.CORSAllowCredentials, err = getBoolEnv(src, "CORS_ALLOW_CREDENTIALS", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.CORSMaxAge, err = getDurationEnv(src, "CORS_MAX_AGE", defaultCORSMaxAge)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.RateLimitRPS, err = getFloatEnv(src, "RATE_LIMIT_RPS", defaultRateLimitRPS)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.RateLimitBurst, err = getIntEnv(src, "RATE_LIMIT_BURST", defaultRateLimitBurst)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.RateLimitHeaders, err = getBoolEnv(src, "RATE_LIMIT_HEADERS", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// TRUST_PROXY_HEADERS is shorthand for a single trusted proxy hop.
+	trustProxyHeaders, err := getBoolEnv(src, "TRUST_PROXY_HEADERS", false)
+	if err != nil {
+		return Config{}, err
+	}
+	defaultTrustedProxyHops := 0
+	if trustProxyHeaders {
+		defaultTrustedProxyHops = 1
+	}
+	cfg.TrustedProxyHops, err = getIntEnv(src, "TRUSTED_PROXY_HOPS", defaultTrustedProxyHops)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.ShutdownDrainDelay, err = getDurationEnv(src, "SHUTDOWN_DRAIN_DELAY", 0)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.ShutdownRejectNew, err = getBoolEnv(src, "SHUTDOWN_REJECT_NEW_REQUESTS", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// HSTS stays off by default so local plain-HTTP development is unaffected.
+	cfg.HSTSMaxAge, err = getDurationEnv(src, "HSTS_MAX_AGE", 0)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.HSTSIncludeSubdomains, err = getBoolEnv(src, "HSTS_INCLUDE_SUBDOMAINS", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.AuthCacheSize, err = getIntEnv(src, "AUTH_CACHE_SIZE", defaultAuthCacheSize)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.AuthCacheMaxTTL, err = getDurationEnv(src, "AUTH_CACHE_MAX_TTL", defaultAuthCacheMaxTTL)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.ProfileCacheSize, err = getIntEnv(src, "PROFILE_CACHE_SIZE", defaultProfileCacheSize)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.ProfileCacheTTL, err = getDurationEnv(src, "PROFILE_CACHE_TTL", defaultProfileCacheTTL)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.AuthBreakerFailures, err = getIntEnv(src, "AUTH_BREAKER_FAILURE_THRESHOLD", defaultAuthBreakerFailures)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.AuthBreakerOpenTime, err = getDurationEnv(src, "AUTH_BREAKER_OPEN_TIMEOUT", defaultAuthBreakerOpen)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.OTLPInsecure, err = getBoolEnv(src, "OTEL_EXPORTER_OTLP_INSECURE", false)
 	if err != nil {
 		return Config{}, err
 	}
@@ -52,18 +287,93 @@ func Load() (Config, error) {
 	if cfg.GRPCDialTimeout <= 0 {
 		return Config{}, fmt.Errorf("GRPC_DIAL_TIMEOUT must be > 0")
 	}
+	if cfg.GRPCMaxRecvMsgSize <= 0 {
+		return Config{}, fmt.Errorf("GRPC_MAX_RECV_MSG_SIZE must be > 0")
+	}
+	if cfg.GRPCKeepaliveTime < 0 || cfg.GRPCKeepaliveTimeout < 0 {
+		return Config{}, fmt.Errorf("GRPC_KEEPALIVE_TIME and GRPC_KEEPALIVE_TIMEOUT must be >= 0")
+	}
+	// gRPC clamps retry attempts to 5; 1 disables retries.
+	if cfg.GRPCValidateAttempts < 1 || cfg.GRPCValidateAttempts > 5 {
+		return Config{}, fmt.Errorf("GRPC_VALIDATE_MAX_ATTEMPTS must be between 1 and 5")
+	}
 	if cfg.AuthRPCTimeout <= 0 {
 		return Config{}, fmt.Errorf("AUTH_RPC_TIMEOUT must be > 0")
 	}
+	if cfg.RequestTimeout < 0 {
+		return Config{}, fmt.Errorf("REQUEST_TIMEOUT must be >= 0")
+	}
+	// The auth RPC runs inside the request deadline, so it must be the smaller bound.
+	if cfg.RequestTimeout > 0 && cfg.RequestTimeout <= cfg.AuthRPCTimeout {
+		return Config{}, fmt.Errorf("REQUEST_TIMEOUT must be > AUTH_RPC_TIMEOUT")
+	}
 	if cfg.LogLevel == "" {
 		return Config{}, fmt.Errorf("LOG_LEVEL cannot be empty")
 	}
+	if cfg.LogFormat != "json" && cfg.LogFormat != "console" {
+		return Config{}, fmt.Errorf("LOG_FORMAT must be one of json, console")
+	}
+	if cfg.CORSMaxAge < 0 {
+		return Config{}, fmt.Errorf("CORS_MAX_AGE must be >= 0")
+	}
+	if cfg.CORSAllowCredentials && slices.Contains(cfg.CORSAllowedOrigins, "*") {
+		return Config{}, fmt.Errorf("CORS_ALLOWED_ORIGINS cannot contain * when CORS_ALLOW_CREDENTIALS is enabled")
+	}
+	if cfg.RateLimitRPS < 0 {
+		return Config{}, fmt.Errorf("RATE_LIMIT_RPS must be >= 0")
+	}
+	if cfg.RateLimitRPS > 0 && cfg.RateLimitBurst <= 0 {
+		return Config{}, fmt.Errorf("RATE_LIMIT_BURST must be > 0")
+	}
+	if cfg.ShutdownDrainDelay < 0 {
+		return Config{}, fmt.Errorf("SHUTDOWN_DRAIN_DELAY must be >= 0")
+	}
+	if cfg.HSTSMaxAge < 0 {
+		return Config{}, fmt.Errorf("HSTS_MAX_AGE must be >= 0")
+	}
+	if cfg.AuthCacheSize < 0 {
+		return Config{}, fmt.Errorf("AUTH_CACHE_SIZE must be >= 0")
+	}
+	if cfg.AuthCacheSize > 0 && cfg.AuthCacheMaxTTL <= 0 {
+		return Config{}, fmt.Errorf("AUTH_CACHE_MAX_TTL must be > 0")
+	}
+	if cfg.ProfileCacheSize < 0 {
+		return Config{}, fmt.Errorf("PROFILE_CACHE_SIZE must be >= 0")
+	}
+	if cfg.ProfileCacheSize > 0 && cfg.ProfileCacheTTL <= 0 {
+		return Config{}, fmt.Errorf("PROFILE_CACHE_TTL must be > 0")
+	}
+	if cfg.AuthBreakerFailures < 0 {
+		return Config{}, fmt.Errorf("AUTH_BREAKER_FAILURE_THRESHOLD must be >= 0")
+	}
+	if cfg.AuthBreakerFailures > 0 && cfg.AuthBreakerOpenTime <= 0 {
+		return Config{}, fmt.Errorf("AUTH_BREAKER_OPEN_TIMEOUT must be > 0")
+	}
+	if cfg.AccessLogSampleRate <= 0 || cfg.AccessLogSampleRate > 1 {
+		return Config{}, fmt.Errorf("ACCESS_LOG_SAMPLE_RATE must be in (0, 1]")
+	}
+	if cfg.AccessLogSlowThreshold < 0 {
+		return Config{}, fmt.Errorf("ACCESS_LOG_SLOW_THRESHOLD must be >= 0")
+	}
+	if cfg.TrustedProxyHops < 0 {
+		return Config{}, fmt.Errorf("TRUSTED_PROXY_HOPS must be >= 0")
+	}
+	switch cfg.AccessLogFormat {
+	case "json", "common", "combined":
+	default:
+		return Config{}, fmt.Errorf("ACCESS_LOG_FORMAT must be one of json, common, combined")
+	}
+	switch cfg.ErrorFormat {
+	case "json", "problemjson":
+	default:
+		return Config{}, fmt.Errorf("ERROR_FORMAT must be one of json, problemjson")
+	}
 
 	return cfg, nil
 }
 
-func getDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
-	value := strings.TrimSpace(os.Getenv(key))
+func getDurationEnv(src configfile.Source, key string, fallback time.Duration) (time.Duration, error) {
+	value := src.Lookup(key)
 	if value == "" {
 		return fallback, nil
 	}
@@ -75,8 +385,75 @@ func getDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
 	return duration, nil
 }
 
-func getEnv(key, fallback string) string {
-	value := strings.TrimSpace(os.Getenv(key))
+func getIntEnv(src configfile.Source, key string, fallback int) (int, error) {
+	value := src.Lookup(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getFloatEnv(src configfile.Source, key string, fallback float64) (float64, error) {
+	value := src.Lookup(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getBoolEnv(src configfile.Source, key string, fallback bool) (bool, error) {
+	value := src.Lookup(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getListEnv(src configfile.Source, key, fallback string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(src, key, fallback), ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getPrefixListEnv(src configfile.Source, key string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range strings.Split(src.Lookup(key), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", key, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func getEnv(src configfile.Source, key, fallback string) string {
+	value := src.Lookup(key)
 	if value == "" {
 		return fallback
 	}
@@ -0,0 +1,215 @@
+package gatewayhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	usersclient "github.com/ozankenangungor/go-commerce/internal/gateway/clients/users"
+	gatewaymiddleware "github.com/ozankenangungor/go-commerce/internal/gateway/http/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxAuthRequestBodyBytes = 1 << 20
+
+// AuthClient proxies credential flows to the user service.
+type AuthClient interface {
+	Register(ctx context.Context, email, password, name, requestID string) (usersclient.AuthResult, error)
+	Login(ctx context.Context, email, password, requestID string) (usersclient.AuthResult, error)
+	RefreshToken(ctx context.Context, refreshToken, requestID string) (usersclient.Tokens, error)
+}
+
+type registerRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+}
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type authUserResponse struct {
+	UserID    string     `json:"user_id"`
+	Email     string     `json:"email"`
+	Name      string     `json:"name"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type authTokensResponse struct {
+	AccessToken             string `json:"access_token"`
+	RefreshToken            string `json:"refresh_token"`
+	AccessExpiresInSeconds  int64  `json:"access_expires_in_seconds"`
+	RefreshExpiresInSeconds int64  `json:"refresh_expires_in_seconds"`
+}
+
+type authResponse struct {
+	User   authUserResponse   `json:"user"`
+	Tokens authTokensResponse `json:"tokens"`
+}
+
+type refreshResponse struct {
+	Tokens authTokensResponse `json:"tokens"`
+}
+
+// authHandlers serves the unauthenticated /v1/auth endpoints.
+type authHandlers struct {
+	client     AuthClient
+	rpcTimeout time.Duration
+}
+
+func (h authHandlers) register(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Email) == "" || req.Password == "" || strings.TrimSpace(req.Name) == "" {
+		gatewaymiddleware.WriteError(w, r, http.StatusBadRequest, "validation_failed", "request validation failed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.rpcTimeout)
+	defer cancel()
+
+	result, err := h.client.Register(ctx, req.Email, req.Password, req.Name, gatewaymiddleware.RequestIDFromContext(r.Context()))
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newAuthResponse(result))
+}
+
+func (h authHandlers) login(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Email) == "" || req.Password == "" {
+		gatewaymiddleware.WriteError(w, r, http.StatusBadRequest, "validation_failed", "request validation failed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.rpcTimeout)
+	defer cancel()
+
+	result, err := h.client.Login(ctx, req.Email, req.Password, gatewaymiddleware.RequestIDFromContext(r.Context()))
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAuthResponse(result))
+}
+
+func (h authHandlers) refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		gatewaymiddleware.WriteError(w, r, http.StatusBadRequest, "validation_failed", "request validation failed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.rpcTimeout)
+	defer cancel()
+
+	tokens, err := h.client.RefreshToken(ctx, req.RefreshToken, gatewaymiddleware.RequestIDFromContext(r.Context()))
+	if err != nil {
+		writeAuthError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, refreshResponse{Tokens: newAuthTokensResponse(tokens)})
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAuthRequestBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		gatewaymiddleware.WriteError(w, r, http.StatusBadRequest, "invalid_request", "request body must be valid JSON")
+		return false
+	}
+	return true
+}
+
+// authValidationCodes are the contract codes the user service returns for input it rejects.
+var authValidationCodes = map[string]bool{
+	"AUTH_MISSING_EMAIL":    true,
+	"AUTH_MISSING_PASSWORD": true,
+	"AUTH_MISSING_NAME":     true,
+	"AUTH_INVALID_EMAIL":    true,
+	"AUTH_WEAK_PASSWORD":    true,
+}
+
+// writeAuthError translates user service failures into HTTP responses. A contract error
+// envelope means the service handled the request and rejected it, so unknown codes map to
+// 400; 500 is reserved for transport and internal failures.
+func writeAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	var contractErr *usersclient.ContractError
+	if errors.As(err, &contractErr) {
+		switch code := contractErr.Code(); {
+		case code == "AUTH_EMAIL_TAKEN":
+			gatewaymiddleware.WriteError(w, r, http.StatusConflict, "email_taken", "email is already registered")
+		case code == "AUTH_INVALID_CREDENTIALS":
+			gatewaymiddleware.WriteError(w, r, http.StatusUnauthorized, "invalid_credentials", "invalid email or password")
+		case code == "AUTH_INVALID_REFRESH_TOKEN":
+			gatewaymiddleware.WriteError(w, r, http.StatusUnauthorized, "invalid_refresh_token", "refresh token is invalid or expired")
+		case code == usersclient.CodeUserNotFound:
+			gatewaymiddleware.WriteError(w, r, http.StatusNotFound, "user_not_found", "user not found")
+		case authValidationCodes[code]:
+			gatewaymiddleware.WriteError(w, r, http.StatusBadRequest, "validation_failed", "request validation failed")
+		default:
+			gatewaymiddleware.WriteError(w, r, http.StatusBadRequest, "request_rejected", "request was rejected")
+		}
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		gatewaymiddleware.WriteError(w, r, http.StatusServiceUnavailable, "auth_unavailable", "authentication service unavailable")
+		return
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		gatewaymiddleware.WriteError(w, r, http.StatusServiceUnavailable, "auth_unavailable", "authentication service unavailable")
+	case codes.InvalidArgument:
+		gatewaymiddleware.WriteError(w, r, http.StatusBadRequest, "validation_failed", "request validation failed")
+	default:
+		gatewaymiddleware.WriteError(w, r, http.StatusInternalServerError, "internal_error", "internal error")
+	}
+}
+
+func newAuthResponse(result usersclient.AuthResult) authResponse {
+	return authResponse{
+		User:   newAuthUserResponse(result.User),
+		Tokens: newAuthTokensResponse(result.Tokens),
+	}
+}
+
+func newAuthUserResponse(user usersclient.User) authUserResponse {
+	resp := authUserResponse{
+		UserID: user.UserID,
+		Email:  user.Email,
+		Name:   user.Name,
+	}
+	if !user.CreatedAt.IsZero() {
+		createdAt := user.CreatedAt.UTC()
+		resp.CreatedAt = &createdAt
+	}
+	return resp
+}
+
+func newAuthTokensResponse(tokens usersclient.Tokens) authTokensResponse {
+	return authTokensResponse{
+		AccessToken:             tokens.AccessToken,
+		RefreshToken:            tokens.RefreshToken,
+		AccessExpiresInSeconds:  tokens.AccessExpiresInSeconds,
+		RefreshExpiresInSeconds: tokens.RefreshExpiresInSeconds,
+	}
+}
@@ -0,0 +1,231 @@
+package gatewayhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	usersclient "github.com/ozankenangungor/go-commerce/internal/gateway/clients/users"
+	"github.com/ozankenangungor/go-commerce/internal/gateway/config"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type stubAuthClient struct {
+	result usersclient.AuthResult
+	err    error
+}
+
+func (s stubAuthClient) Register(ctx context.Context, email, password, name, requestID string) (usersclient.AuthResult, error) {
+	return s.result, s.err
+}
+
+func (s stubAuthClient) Login(ctx context.Context, email, password, requestID string) (usersclient.AuthResult, error) {
+	return s.result, s.err
+}
+
+func (s stubAuthClient) RefreshToken(ctx context.Context, refreshToken, requestID string) (usersclient.Tokens, error) {
+	return s.result.Tokens, s.err
+}
+
+type originRecordingAuthClient struct {
+	stubAuthClient
+	origin usersclient.Origin
+}
+
+func (c *originRecordingAuthClient) Login(ctx context.Context, email, password, requestID string) (usersclient.AuthResult, error) {
+	c.origin, _ = usersclient.OriginFromContext(ctx)
+	return c.result, c.err
+}
+
+func TestLoginForwardsCallerOrigin(t *testing.T) {
+	client := &originRecordingAuthClient{}
+	router := NewRouter(config.Config{AccessLogFormat: "json", TrustedProxyHops: 1}, Dependencies{
+		Logger:         zerolog.Nop(),
+		TokenValidator: stubTokenValidator{userID: "user-123"},
+		AuthClient:     client,
+		AuthRPCTimeout: time.Second,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/login", strings.NewReader(`{"email":"jane@example.com","password":"secret"}`))
+	req.RemoteAddr = "10.0.0.1:4000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req.Header.Set("User-Agent", "shop-app/1.2")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if client.origin.ClientIP != "203.0.113.7" || client.origin.UserAgent != "shop-app/1.2" {
+		t.Fatalf("unexpected origin: %#v", client.origin)
+	}
+}
+
+func TestRefreshReturnsTokenPairWithoutAuth(t *testing.T) {
+	router := newAuthRouter(stubAuthClient{result: usersclient.AuthResult{
+		Tokens: usersclient.Tokens{AccessToken: "access-2", RefreshToken: "refresh-2"},
+	}})
+
+	rr := serveAuthRequest(router, "/v1/auth/refresh", `{"refresh_token":"refresh-1"}`)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var body refreshResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	if body.Tokens.AccessToken != "access-2" || body.Tokens.RefreshToken != "refresh-2" {
+		t.Fatalf("unexpected tokens: %#v", body.Tokens)
+	}
+}
+
+func TestRegisterReturnsUserAndTokensWithoutAuth(t *testing.T) {
+	router := newAuthRouter(stubAuthClient{result: usersclient.AuthResult{
+		User:   usersclient.User{UserID: "user-123", Email: "jane@example.com", Name: "Jane"},
+		Tokens: usersclient.Tokens{AccessToken: "access", RefreshToken: "refresh", AccessExpiresInSeconds: 900},
+	}})
+
+	rr := serveAuthRequest(router, "/v1/auth/register", `{"email":"jane@example.com","password":"secret","name":"Jane"}`)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var body authResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	if body.User.UserID != "user-123" || body.User.Email != "jane@example.com" {
+		t.Fatalf("unexpected user: %#v", body.User)
+	}
+	if body.Tokens.AccessToken != "access" || body.Tokens.RefreshToken != "refresh" || body.Tokens.AccessExpiresInSeconds != 900 {
+		t.Fatalf("unexpected tokens: %#v", body.Tokens)
+	}
+}
+
+func TestAuthEndpointsTranslateErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "email taken",
+			path:       "/v1/auth/register",
+			body:       `{"email":"jane@example.com","password":"secret","name":"Jane"}`,
+			err:        &usersclient.ContractError{Op: "register", ErrCode: "AUTH_EMAIL_TAKEN"},
+			wantStatus: http.StatusConflict,
+			wantError:  "email_taken",
+		},
+		{
+			name:       "invalid credentials",
+			path:       "/v1/auth/login",
+			body:       `{"email":"jane@example.com","password":"wrong"}`,
+			err:        &usersclient.ContractError{Op: "login", ErrCode: "AUTH_INVALID_CREDENTIALS"},
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "invalid_credentials",
+		},
+		{
+			name:       "service validation",
+			path:       "/v1/auth/register",
+			body:       `{"email":"jane@example.com","password":"x","name":"Jane"}`,
+			err:        &usersclient.ContractError{Op: "register", ErrCode: "AUTH_WEAK_PASSWORD"},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "validation_failed",
+		},
+		{
+			name:       "unknown contract code",
+			path:       "/v1/auth/register",
+			body:       `{"email":"jane@example.com","password":"secret","name":"Jane"}`,
+			err:        &usersclient.ContractError{Op: "register", ErrCode: "AUTH_SOMETHING_NEW"},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "request_rejected",
+		},
+		{
+			name:       "internal failure",
+			path:       "/v1/auth/login",
+			body:       `{"email":"jane@example.com","password":"secret"}`,
+			err:        status.Error(codes.Internal, "boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantError:  "internal_error",
+		},
+		{
+			name:       "missing name",
+			path:       "/v1/auth/register",
+			body:       `{"email":"jane@example.com","password":"secret"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "validation_failed",
+		},
+		{
+			name:       "invalid refresh token",
+			path:       "/v1/auth/refresh",
+			body:       `{"refresh_token":"revoked"}`,
+			err:        &usersclient.ContractError{Op: "refresh token", ErrCode: "AUTH_INVALID_REFRESH_TOKEN"},
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "invalid_refresh_token",
+		},
+		{
+			name:       "missing fields",
+			path:       "/v1/auth/login",
+			body:       `{"email":""}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "validation_failed",
+		},
+		{
+			name:       "malformed json",
+			path:       "/v1/auth/login",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "user service unavailable",
+			path:       "/v1/auth/login",
+			body:       `{"email":"jane@example.com","password":"secret"}`,
+			err:        status.Error(codes.Unavailable, "down"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantError:  "auth_unavailable",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := serveAuthRequest(newAuthRouter(stubAuthClient{err: tc.err}), tc.path, tc.body)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), `"code":"`+tc.wantError+`"`) {
+				t.Fatalf("expected error %q, got %s", tc.wantError, rr.Body.String())
+			}
+		})
+	}
+}
+
+func newAuthRouter(client AuthClient) http.Handler {
+	return NewRouter(config.Config{AccessLogFormat: "json"}, Dependencies{
+		Logger:         zerolog.Nop(),
+		TokenValidator: stubTokenValidator{userID: "user-123"},
+		AuthClient:     client,
+		AuthRPCTimeout: time.Second,
+	}, nil)
+}
+
+func serveAuthRequest(router http.Handler, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
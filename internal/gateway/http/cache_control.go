@@ -0,0 +1,30 @@
+package gatewayhttp
+
+import (
+	"net/http"
+	"strings"
+
+	usersclient "github.com/ozankenangungor/go-commerce/internal/gateway/clients/users"
+)
+
+// honorNoCache lets a client force a fresh token validation with "Cache-Control: no-cache",
+// e.g. right after a role change, without disabling the validation cache globally.
+func honorNoCache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hasNoCacheDirective(r.Header.Values("Cache-Control")) {
+			r = r.WithContext(usersclient.WithFreshValidation(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func hasNoCacheDirective(values []string) bool {
+	for _, value := range values {
+		for _, directive := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package gatewayhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	usersclient "github.com/ozankenangungor/go-commerce/internal/gateway/clients/users"
+	"github.com/ozankenangungor/go-commerce/internal/gateway/config"
+	"github.com/rs/zerolog"
+)
+
+type countingIdentityValidator struct {
+	calls int
+}
+
+func (c *countingIdentityValidator) ValidateAccessTokenIdentity(ctx context.Context, accessToken string, requestID string) (usersclient.Identity, error) {
+	c.calls++
+	return usersclient.Identity{UserID: "user-123", Roles: []string{"customer"}}, nil
+}
+
+func TestNoCacheBypassesPopulatedValidationCache(t *testing.T) {
+	upstream := &countingIdentityValidator{}
+	router := NewRouter(config.Config{AccessLogFormat: "json"}, Dependencies{
+		Logger: zerolog.Nop(),
+		TokenValidator: usersclient.NewCachingValidator(upstream, usersclient.ValidationCacheOptions{
+			Size:   10,
+			MaxTTL: time.Minute,
+		}),
+		AuthRPCTimeout: time.Second,
+	}, nil)
+
+	serveMe := func(cacheControl string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		if cacheControl != "" {
+			req.Header.Set("Cache-Control", cacheControl)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rr.Code)
+		}
+	}
+
+	serveMe("")
+	serveMe("")
+	if upstream.calls != 1 {
+		t.Fatalf("expected normal request to hit the cache, got %d rpcs", upstream.calls)
+	}
+
+	serveMe("max-age=0, No-Cache")
+	if upstream.calls != 2 {
+		t.Fatalf("expected no-cache request to bypass the cache, got %d rpcs", upstream.calls)
+	}
+
+	serveMe("")
+	if upstream.calls != 2 {
+		t.Fatalf("expected cache to stay populated after a no-cache request, got %d rpcs", upstream.calls)
+	}
+}
@@ -0,0 +1,54 @@
+package gatewayhttp
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	usersclient "github.com/ozankenangungor/go-commerce/internal/gateway/clients/users"
+	gatewaymiddleware "github.com/ozankenangungor/go-commerce/internal/gateway/http/middleware"
+)
+
+// ProfileClient fetches a user's profile for /v1/me.
+type ProfileClient interface {
+	GetProfile(ctx context.Context, userID string, requestID string) (usersclient.User, error)
+}
+
+type meResponse struct {
+	UserID string            `json:"user_id"`
+	Roles  []string          `json:"roles"`
+	User   *authUserResponse `json:"user,omitempty"`
+}
+
+// meHandler serves /v1/me from the validated token. With a non-nil profiles client it also
+// fetches the caller's profile, which costs an extra RPC and database read per request.
+func meHandler(profiles ProfileClient, rpcTimeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := gatewaymiddleware.UserIDFromContext(r.Context())
+		if !ok {
+			gatewaymiddleware.WriteError(w, r, http.StatusUnauthorized, "unauthorized", "missing or invalid access token")
+			return
+		}
+
+		roles, ok := gatewaymiddleware.RolesFromContext(r.Context())
+		if !ok {
+			roles = []string{}
+		}
+
+		resp := meResponse{UserID: userID, Roles: roles}
+		if profiles != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), rpcTimeout)
+			defer cancel()
+
+			user, err := profiles.GetProfile(ctx, userID, gatewaymiddleware.RequestIDFromContext(r.Context()))
+			if err != nil {
+				writeAuthError(w, r, err)
+				return
+			}
+			profile := newAuthUserResponse(user)
+			resp.User = &profile
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,112 @@
+package gatewayhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	usersclient "github.com/ozankenangungor/go-commerce/internal/gateway/clients/users"
+	"github.com/ozankenangungor/go-commerce/internal/gateway/config"
+	"github.com/rs/zerolog"
+)
+
+type stubProfileClient struct {
+	calls       int
+	hadDeadline bool
+	user        usersclient.User
+	err         error
+}
+
+func (s *stubProfileClient) GetProfile(ctx context.Context, userID string, requestID string) (usersclient.User, error) {
+	s.calls++
+	_, s.hadDeadline = ctx.Deadline()
+	return s.user, s.err
+}
+
+func TestMeIncludesProfileWhenEnabled(t *testing.T) {
+	profiles := &stubProfileClient{user: usersclient.User{
+		UserID:    "user-123",
+		Email:     "ada@example.com",
+		Name:      "Ada",
+		CreatedAt: time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC),
+	}}
+	rr := serveMe(t, true, profiles)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !profiles.hadDeadline {
+		t.Fatal("expected profile fetch to run under the auth RPC timeout")
+	}
+
+	var body struct {
+		UserID string `json:"user_id"`
+		User   struct {
+			Email     string `json:"email"`
+			Name      string `json:"name"`
+			CreatedAt string `json:"created_at"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.UserID != "user-123" || body.User.Email != "ada@example.com" || body.User.Name != "Ada" {
+		t.Fatalf("unexpected body %s", rr.Body.String())
+	}
+	if body.User.CreatedAt != "2026-01-02T03:04:05Z" {
+		t.Fatalf("expected created_at 2026-01-02T03:04:05Z, got %q", body.User.CreatedAt)
+	}
+}
+
+func TestMeSkipsProfileWhenDisabled(t *testing.T) {
+	profiles := &stubProfileClient{}
+	rr := serveMe(t, false, profiles)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if profiles.calls != 0 {
+		t.Fatalf("expected no profile fetch, got %d", profiles.calls)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if _, ok := body["user"]; ok {
+		t.Fatalf("expected no user object, got %s", rr.Body.String())
+	}
+}
+
+func TestMeMapsMissingProfileToNotFound(t *testing.T) {
+	profiles := &stubProfileClient{err: &usersclient.ContractError{Op: "get profile", ErrCode: usersclient.CodeUserNotFound}}
+	rr := serveMe(t, true, profiles)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"code":"user_not_found"`) {
+		t.Fatalf("expected user_not_found error, got %s", rr.Body.String())
+	}
+}
+
+func serveMe(t *testing.T, includeProfile bool, profiles ProfileClient) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := NewRouter(config.Config{AccessLogFormat: "json", MeIncludeProfile: includeProfile}, Dependencies{
+		Logger:         zerolog.Nop(),
+		TokenValidator: stubTokenValidator{userID: "user-123", roles: []string{"customer"}},
+		ProfileClient:  profiles,
+		AuthRPCTimeout: time.Second,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
@@ -14,6 +14,11 @@ import (
 
 type userIDContextKey struct{}
 type rolesContextKey struct{}
+type userIDSlotContextKey struct{}
+
+type userIDSlot struct {
+	userID string
+}
 
 type codedError interface {
 	error
@@ -38,7 +43,7 @@ func Auth(validator TokenValidator, authRPCTimeout time.Duration) func(http.Hand
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token, ok := extractBearerToken(r.Header.Get("Authorization"))
 			if !ok {
-				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				WriteError(w, r, http.StatusUnauthorized, "unauthorized", "missing or invalid access token")
 				return
 			}
 
@@ -49,18 +54,22 @@ func Auth(validator TokenValidator, authRPCTimeout time.Duration) func(http.Hand
 			userID, roles, err := validator.ValidateAccessToken(rpcCtx, token, requestID)
 			if err != nil {
 				if isInvalidTokenError(err) {
-					writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+					WriteError(w, r, http.StatusUnauthorized, "unauthorized", "missing or invalid access token")
 					return
 				}
 				if isUnavailableError(err) {
-					writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "auth_unavailable"})
+					WriteError(w, r, http.StatusServiceUnavailable, "auth_unavailable", "authentication service unavailable")
 					return
 				}
 
-				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				WriteError(w, r, http.StatusUnauthorized, "unauthorized", "missing or invalid access token")
 				return
 			}
 
+			if slot, ok := r.Context().Value(userIDSlotContextKey{}).(*userIDSlot); ok {
+				slot.userID = userID
+			}
+
 			ctx := context.WithValue(r.Context(), userIDContextKey{}, userID)
 			ctx = context.WithValue(ctx, rolesContextKey{}, append([]string(nil), roles...))
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -80,6 +89,15 @@ func UserIDFromContext(ctx context.Context) (string, bool) {
 	return userID, true
 }
 
+// WithUserIDSlot returns a context in which Auth records the authenticated user id,
+// so outer middleware can read it after the downstream handler returns.
+func WithUserIDSlot(ctx context.Context) (context.Context, func() string) {
+	slot := &userIDSlot{}
+	return context.WithValue(ctx, userIDSlotContextKey{}, slot), func() string {
+		return slot.userID
+	}
+}
+
 // RolesFromContext returns authenticated roles from context.
 func RolesFromContext(ctx context.Context) ([]string, bool) {
 	if ctx == nil {
@@ -127,13 +145,17 @@ func isUnavailableError(err error) bool {
 }
 
 func writeJSON(w http.ResponseWriter, statusCode int, payload any) {
+	writeJSONContent(w, statusCode, "application/json", payload)
+}
+
+func writeJSONContent(w http.ResponseWriter, statusCode int, contentType string, payload any) {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(statusCode)
 	if _, writeErr := w.Write(body); writeErr != nil {
 		return
@@ -186,12 +186,15 @@ func newProtectedHandler(t *testing.T, validator TokenValidator) http.Handler {
 func assertErrorBody(t *testing.T, rr *httptest.ResponseRecorder, want string) {
 	t.Helper()
 
-	var payload map[string]string
+	var payload ErrorResponse
 	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
 		t.Fatalf("unmarshal error body: %v", err)
 	}
 
-	if payload["error"] != want {
-		t.Fatalf("expected error %q, got %q", want, payload["error"])
+	if payload.Error.Code != want {
+		t.Fatalf("expected error code %q, got %q", want, payload.Error.Code)
+	}
+	if payload.Error.Message == "" {
+		t.Fatal("expected error message to be set")
 	}
 }
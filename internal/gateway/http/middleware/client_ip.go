@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP resolves the caller's IP address. trustedHops is the number of trusted proxies in
+// front of the gateway: each appends the address it received the request from to
+// X-Forwarded-For, so the client is the trustedHops-th entry from the right. Entries further
+// left are client-supplied and ignored. With trustedHops <= 0, or a chain shorter than the
+// configured depth, the connection's remote address is used.
+func ClientIP(r *http.Request, trustedHops int) string {
+	if trustedHops > 0 {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			entries := strings.Split(strings.Join(forwarded, ","), ",")
+			if len(entries) >= trustedHops {
+				if client := strings.TrimSpace(entries[len(entries)-trustedHops]); client != "" {
+					return client
+				}
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
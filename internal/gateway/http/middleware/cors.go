@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the CORS middleware.
+type CORSOptions struct {
+	// AllowedOrigins lists exact origins; "*" allows any origin. Empty disables CORS.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORS echoes allowed origins and answers preflight requests with 204 before they reach
+// route handlers, so preflights never hit Auth.
+func CORS(opts CORSOptions) func(http.Handler) http.Handler {
+	allowAny := false
+	allowed := make(map[string]struct{}, len(opts.AllowedOrigins))
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			allowAny = true
+			continue
+		}
+		allowed[strings.ToLower(origin)] = struct{}{}
+	}
+
+	allowMethods := strings.Join(opts.AllowedMethods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+	maxAge := ""
+	if opts.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(opts.MaxAge.Seconds()))
+	}
+
+	return func(next http.Handler) http.Handler {
+		if !allowAny && len(allowed) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			headers := w.Header()
+			headers.Add("Vary", "Origin")
+
+			if _, ok := allowed[strings.ToLower(origin)]; !ok && !allowAny {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			headers.Set("Access-Control-Allow-Origin", origin)
+			if opts.AllowCredentials {
+				headers.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			headers.Add("Vary", "Access-Control-Request-Method")
+			headers.Add("Vary", "Access-Control-Request-Headers")
+			if allowMethods != "" {
+				headers.Set("Access-Control-Allow-Methods", allowMethods)
+			}
+			if allowHeaders != "" {
+				headers.Set("Access-Control-Allow-Headers", allowHeaders)
+			}
+			if maxAge != "" {
+				headers.Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCORSPreflightSkipsAuth(t *testing.T) {
+	called := false
+	handler := newCORSHandler(t, CORSOptions{
+		AllowedOrigins: []string{"https://shop.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		MaxAge:         10 * time.Minute,
+	}, &called)
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/me", nil)
+	req.Header.Set("Origin", "https://shop.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rr.Code)
+	}
+	if called {
+		t.Fatal("preflight request must not reach the Auth middleware")
+	}
+	assertHeader(t, rr, "Access-Control-Allow-Origin", "https://shop.example.com")
+	assertHeader(t, rr, "Access-Control-Allow-Methods", "GET, POST")
+	assertHeader(t, rr, "Access-Control-Allow-Headers", "Authorization, Content-Type")
+	assertHeader(t, rr, "Access-Control-Max-Age", "600")
+	assertHeader(t, rr, "Access-Control-Allow-Credentials", "")
+}
+
+func TestCORSEchoesAllowedOriginWithCredentials(t *testing.T) {
+	called := false
+	handler := newCORSHandler(t, CORSOptions{
+		AllowedOrigins:   []string{"https://shop.example.com"},
+		AllowCredentials: true,
+	}, &called)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.Header.Set("Origin", "https://shop.example.com")
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !called {
+		t.Fatal("expected actual request to pass through Auth")
+	}
+	assertHeader(t, rr, "Access-Control-Allow-Origin", "https://shop.example.com")
+	assertHeader(t, rr, "Access-Control-Allow-Credentials", "true")
+}
+
+func TestCORSIgnoresDisallowedOrigin(t *testing.T) {
+	called := false
+	handler := newCORSHandler(t, CORSOptions{
+		AllowedOrigins: []string{"https://shop.example.com"},
+	}, &called)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assertHeader(t, rr, "Access-Control-Allow-Origin", "")
+}
+
+func TestCORSDisabledWithoutOrigins(t *testing.T) {
+	called := false
+	handler := newCORSHandler(t, CORSOptions{}, &called)
+
+	req := httptest.NewRequest(http.MethodOptions, "/v1/me", nil)
+	req.Header.Set("Origin", "https://shop.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code == http.StatusNoContent {
+		t.Fatal("expected preflight not to be answered when CORS is disabled")
+	}
+	assertHeader(t, rr, "Access-Control-Allow-Origin", "")
+}
+
+func newCORSHandler(t *testing.T, opts CORSOptions, validatorCalled *bool) http.Handler {
+	t.Helper()
+
+	router := chi.NewRouter()
+	router.Use(RequestID)
+	router.Use(CORS(opts))
+	router.With(Auth(fakeTokenValidator{
+		validateFunc: func(ctx context.Context, accessToken string, requestID string) (string, []string, error) {
+			*validatorCalled = true
+			return "user-123", []string{"customer"}, nil
+		},
+	}, time.Second)).Get("/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return router
+}
+
+func assertHeader(t *testing.T, rr *httptest.ResponseRecorder, key, want string) {
+	t.Helper()
+
+	if got := rr.Header().Get(key); got != want {
+		t.Fatalf("expected header %s=%q, got %q", key, want, got)
+	}
+}
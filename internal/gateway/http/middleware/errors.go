@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// ErrorResponse is the JSON envelope for every gateway error response.
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail carries a stable machine-readable code alongside a human-readable message.
+type ErrorDetail struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ProblemDetails is an RFC 7807 application/problem+json body. Code is an extension member
+// carrying the same stable code as ErrorDetail.
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// ErrorFormat selects how WriteError renders error bodies.
+type ErrorFormat string
+
+const (
+	// ErrorFormatJSON renders the ErrorResponse envelope.
+	ErrorFormatJSON ErrorFormat = "json"
+	// ErrorFormatProblemJSON renders RFC 7807 ProblemDetails.
+	ErrorFormatProblemJSON ErrorFormat = "problemjson"
+)
+
+type errorFormatContextKey struct{}
+
+// WithErrorFormat makes WriteError render errors for the rest of the chain in format. It
+// should be installed first so that every middleware's errors share the format.
+func WithErrorFormat(format ErrorFormat) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), errorFormatContextKey{}, format)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WriteError writes the standard error envelope, tagged with the request id from r's context.
+// Under ErrorFormatProblemJSON it writes RFC 7807 problem details instead, with the request
+// id as the instance.
+func WriteError(w http.ResponseWriter, r *http.Request, statusCode int, code, message string) {
+	requestID := RequestIDFromContext(r.Context())
+
+	if format, _ := r.Context().Value(errorFormatContextKey{}).(ErrorFormat); format == ErrorFormatProblemJSON {
+		writeJSONContent(w, statusCode, "application/problem+json", ProblemDetails{
+			Type:     "about:blank",
+			Title:    http.StatusText(statusCode),
+			Status:   statusCode,
+			Detail:   message,
+			Instance: requestID,
+			Code:     code,
+		})
+		return
+	}
+
+	writeJSON(w, statusCode, ErrorResponse{
+		Error: ErrorDetail{
+			Code:      code,
+			Message:   message,
+			RequestID: requestID,
+		},
+	})
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteErrorIncludesRequestID(t *testing.T) {
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, http.StatusUnauthorized, "unauthorized", "missing or invalid access token")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.Header.Set(RequestIDHeader, "req-abc")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rr.Code)
+	}
+
+	var body ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	want := ErrorDetail{Code: "unauthorized", Message: "missing or invalid access token", RequestID: "req-abc"}
+	if body.Error != want {
+		t.Fatalf("expected %#v, got %#v", want, body.Error)
+	}
+}
+
+func TestWriteErrorProblemJSON(t *testing.T) {
+	handler := WithErrorFormat(ErrorFormatProblemJSON)(RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, http.StatusUnauthorized, "unauthorized", "missing or invalid access token")
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.Header.Set(RequestIDHeader, "req-abc")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Fatalf("expected application/problem+json, got %q", got)
+	}
+
+	var body ProblemDetails
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	want := ProblemDetails{
+		Type:     "about:blank",
+		Title:    "Unauthorized",
+		Status:   http.StatusUnauthorized,
+		Detail:   "missing or invalid access token",
+		Instance: "req-abc",
+		Code:     "unauthorized",
+	}
+	if body != want {
+		t.Fatalf("expected %#v, got %#v", want, body)
+	}
+}
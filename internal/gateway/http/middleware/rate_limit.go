@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const defaultRateLimitIdleTTL = 5 * time.Minute
+
+// RateLimitOptions configures the per-client-IP rate limiter.
+type RateLimitOptions struct {
+	// RequestsPerSecond is the sustained token refill rate. Zero disables rate limiting.
+	RequestsPerSecond float64
+	Burst             int
+
+	// TrustedProxyHops is the number of trusted proxies whose X-Forwarded-For entries are
+	// honoured when resolving the client IP. See ClientIP.
+	TrustedProxyHops int
+
+	// IdleTTL is how long an idle client's limiter is kept before eviction.
+	IdleTTL time.Duration
+
+	// EmitHeaders adds the IETF draft RateLimit-Limit, RateLimit-Remaining and
+	// RateLimit-Reset headers to every response, so clients can slow down before they are
+	// rejected.
+	EmitHeaders bool
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type rateLimiter struct {
+	opts RateLimitOptions
+	now  func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+// RateLimit applies a token bucket per client IP and rejects excess requests with 429
+// and a Retry-After header.
+func RateLimit(opts RateLimitOptions) func(http.Handler) http.Handler {
+	return newRateLimiter(opts, time.Now).middleware
+}
+
+func newRateLimiter(opts RateLimitOptions, now func() time.Time) *rateLimiter {
+	if opts.IdleTTL <= 0 {
+		opts.IdleTTL = defaultRateLimitIdleTTL
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = 1
+	}
+
+	return &rateLimiter{
+		opts:      opts,
+		now:       now,
+		clients:   make(map[string]*clientLimiter),
+		lastSweep: now(),
+	}
+}
+
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	if l.opts.RequestsPerSecond <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := l.now()
+		limiter := l.limiterFor(ClientIP(r, l.opts.TrustedProxyHops), now)
+		reservation := limiter.ReserveN(now, 1)
+
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			l.setHeaders(w.Header(), limiter, now)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			WriteError(w, r, http.StatusTooManyRequests, "rate_limited", "too many requests")
+			return
+		}
+
+		l.setHeaders(w.Header(), limiter, now)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setHeaders reports the client's bucket state: the burst size, whole tokens left, and the
+// seconds until the bucket is full again.
+func (l *rateLimiter) setHeaders(header http.Header, limiter *rate.Limiter, now time.Time) {
+	if !l.opts.EmitHeaders {
+		return
+	}
+
+	tokens := math.Max(limiter.TokensAt(now), 0)
+	reset := math.Ceil((float64(l.opts.Burst) - tokens) / l.opts.RequestsPerSecond)
+
+	header.Set("RateLimit-Limit", strconv.Itoa(l.opts.Burst))
+	header.Set("RateLimit-Remaining", strconv.Itoa(int(math.Floor(tokens))))
+	header.Set("RateLimit-Reset", strconv.Itoa(int(math.Max(reset, 0))))
+}
+
+func (l *rateLimiter) limiterFor(clientIP string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= l.opts.IdleTTL {
+		for ip, client := range l.clients {
+			if now.Sub(client.lastSeen) >= l.opts.IdleTTL {
+				delete(l.clients, ip)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	client, ok := l.clients[clientIP]
+	if !ok {
+		client = &clientLimiter{
+			limiter: rate.NewLimiter(rate.Limit(l.opts.RequestsPerSecond), l.opts.Burst),
+		}
+		l.clients[clientIP] = client
+	}
+	client.lastSeen = now
+	return client.limiter
+}
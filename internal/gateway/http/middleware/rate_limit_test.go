@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestRateLimitRejectsBurstOverflow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	handler := newRateLimitedHandler(RateLimitOptions{RequestsPerSecond: 1, Burst: 2}, clock)
+
+	for i := 0; i < 2; i++ {
+		rr := serveFrom(handler, "203.0.113.7:1000", "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i, rr.Code)
+		}
+	}
+
+	rr := serveFrom(handler, "203.0.113.7:1000", "")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", rr.Code)
+	}
+	assertErrorBody(t, rr, "rate_limited")
+	assertHeader(t, rr, "Retry-After", "1")
+
+	// Other clients have their own bucket.
+	if rr := serveFrom(handler, "198.51.100.2:1000", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected other client to pass, got %d", rr.Code)
+	}
+
+	// Tokens refill over time.
+	clock.now = clock.now.Add(time.Second)
+	if rr := serveFrom(handler, "203.0.113.7:1000", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected request after refill to pass, got %d", rr.Code)
+	}
+}
+
+func TestRateLimitHeadersTrackRemainingBudget(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	handler := newRateLimitedHandler(RateLimitOptions{RequestsPerSecond: 1, Burst: 3, EmitHeaders: true}, clock)
+
+	steps := []struct {
+		advance       time.Duration
+		wantStatus    int
+		wantRemaining string
+		wantReset     string
+	}{
+		{wantStatus: http.StatusOK, wantRemaining: "2", wantReset: "1"},
+		{wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "2"},
+		{wantStatus: http.StatusOK, wantRemaining: "0", wantReset: "3"},
+		{wantStatus: http.StatusTooManyRequests, wantRemaining: "0", wantReset: "3"},
+		{advance: 2 * time.Second, wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "2"},
+	}
+
+	for i, step := range steps {
+		clock.now = clock.now.Add(step.advance)
+		rr := serveFrom(handler, "203.0.113.7:1000", "")
+		if rr.Code != step.wantStatus {
+			t.Fatalf("request %d: expected status %d, got %d", i, step.wantStatus, rr.Code)
+		}
+		assertHeader(t, rr, "RateLimit-Limit", "3")
+		assertHeader(t, rr, "RateLimit-Remaining", step.wantRemaining)
+		assertHeader(t, rr, "RateLimit-Reset", step.wantReset)
+	}
+}
+
+func TestRateLimitHeadersDisabledByDefault(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	handler := newRateLimitedHandler(RateLimitOptions{RequestsPerSecond: 1, Burst: 3}, clock)
+
+	rr := serveFrom(handler, "203.0.113.7:1000", "")
+	for _, name := range []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"} {
+		if got := rr.Header().Get(name); got != "" {
+			t.Fatalf("expected no %s header, got %q", name, got)
+		}
+	}
+}
+
+func TestRateLimitKeysByForwardedForWhenTrusted(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	handler := newRateLimitedHandler(RateLimitOptions{RequestsPerSecond: 1, Burst: 1, TrustedProxyHops: 1}, clock)
+
+	if rr := serveFrom(handler, "10.0.0.1:1000", "203.0.113.7"); rr.Code != http.StatusOK {
+		t.Fatalf("expected first client to pass, got %d", rr.Code)
+	}
+	if rr := serveFrom(handler, "10.0.0.1:1000", "198.51.100.2"); rr.Code != http.StatusOK {
+		t.Fatalf("expected second client behind the same proxy to pass, got %d", rr.Code)
+	}
+	if rr := serveFrom(handler, "10.0.0.1:1000", "203.0.113.7"); rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected repeated client to be limited, got %d", rr.Code)
+	}
+}
+
+func TestRateLimitEvictsIdleClients(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	limiter := newRateLimiter(RateLimitOptions{RequestsPerSecond: 1, Burst: 1, IdleTTL: time.Minute}, clock.Now)
+
+	limiter.limiterFor("203.0.113.7", clock.now)
+	clock.now = clock.now.Add(2 * time.Minute)
+	limiter.limiterFor("198.51.100.2", clock.now)
+
+	if _, ok := limiter.clients["203.0.113.7"]; ok {
+		t.Fatal("expected idle client limiter to be evicted")
+	}
+	if len(limiter.clients) != 1 {
+		t.Fatalf("expected 1 tracked client, got %d", len(limiter.clients))
+	}
+}
+
+func TestClientIPIgnoresForwardedForUnlessTrusted(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.RemoteAddr = "10.0.0.1:1000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 198.51.100.2")
+
+	if got := ClientIP(req, 0); got != "10.0.0.1" {
+		t.Fatalf("expected remote address, got %q", got)
+	}
+	if got := ClientIP(req, 1); got != "198.51.100.2" {
+		t.Fatalf("expected rightmost forwarded entry, got %q", got)
+	}
+}
+
+func TestClientIPTrustedHops(t *testing.T) {
+	tests := []struct {
+		name         string
+		forwardedFor string
+		trustedHops  int
+		want         string
+	}{
+		{
+			name:         "normal chain through two proxies",
+			forwardedFor: "203.0.113.7, 10.0.0.5",
+			trustedHops:  2,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "over-long chain ignores spoofed leading entries",
+			forwardedFor: "1.2.3.4, 5.6.7.8, 203.0.113.7, 10.0.0.5",
+			trustedHops:  2,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "chain shorter than proxy depth falls back to remote address",
+			forwardedFor: "203.0.113.7",
+			trustedHops:  2,
+			want:         "10.0.0.1",
+		},
+		{
+			name:        "direct connection without forwarded header",
+			trustedHops: 2,
+			want:        "10.0.0.1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+			req.RemoteAddr = "10.0.0.1:1000"
+			if tc.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			}
+
+			if got := ClientIP(req, tc.trustedHops); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func newRateLimitedHandler(opts RateLimitOptions, clock *fakeClock) http.Handler {
+	return newRateLimiter(opts, clock.Now).middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func serveFrom(handler http.Handler, remoteAddr string, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
@@ -14,10 +14,23 @@ type requestIDContextKey struct{}
 
 // RequestID attaches a request id to context and response headers.
 func RequestID(next http.Handler) http.Handler {
+	return requestIDHandler(next, false)
+}
+
+// RequestIDWithSuffix behaves like RequestID, but appends a server-generated nonce to
+// client-supplied ids ("<client-id>/<nonce>") so retries reusing an id stay distinguishable.
+func RequestIDWithSuffix(next http.Handler) http.Handler {
+	return requestIDHandler(next, true)
+}
+
+func requestIDHandler(next http.Handler, suffixClientIDs bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestID := r.Header.Get(RequestIDHeader)
-		if requestID == "" {
+		switch {
+		case requestID == "":
 			requestID = newRequestID()
+		case suffixClientIDs:
+			requestID = requestID + "/" + newNonce(4)
 		}
 
 		w.Header().Set(RequestIDHeader, requestID)
@@ -42,3 +55,11 @@ func newRequestID() string {
 	}
 	return "req-" + hex.EncodeToString(raw)
 }
+
+func newNonce(size int) string {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(raw)
+}
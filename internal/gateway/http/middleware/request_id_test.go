@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestRequestIDEchoesClientIDByDefault(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.Header.Set(RequestIDHeader, "client-abc")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if seen != "client-abc" {
+		t.Fatalf("expected context request id client-abc, got %q", seen)
+	}
+	if got := rr.Header().Get(RequestIDHeader); got != "client-abc" {
+		t.Fatalf("expected echoed header client-abc, got %q", got)
+	}
+}
+
+func TestRequestIDWithSuffixAppendsServerNonce(t *testing.T) {
+	var seen []string
+	handler := RequestIDWithSuffix(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, RequestIDFromContext(r.Context()))
+	}))
+
+	pattern := regexp.MustCompile(`^client-abc/[0-9a-f]{8}$`)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+		req.Header.Set(RequestIDHeader, "client-abc")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get(RequestIDHeader); got != seen[i] {
+			t.Fatalf("expected response header %q to match context id %q", got, seen[i])
+		}
+		if !pattern.MatchString(seen[i]) {
+			t.Fatalf("unexpected suffixed request id %q", seen[i])
+		}
+	}
+
+	if seen[0] == seen[1] {
+		t.Fatalf("expected distinct ids for repeated client id, got %q twice", seen[0])
+	}
+}
+
+func TestRequestIDWithSuffixGeneratesMissingID(t *testing.T) {
+	var seen string
+	handler := RequestIDWithSuffix(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/me", nil))
+
+	if !regexp.MustCompile(`^req-[0-9a-f]{24}$`).MatchString(seen) {
+		t.Fatalf("expected generated request id without suffix, got %q", seen)
+	}
+}
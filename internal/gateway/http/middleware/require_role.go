@@ -0,0 +1,38 @@
+package middleware
+
+import "net/http"
+
+// RequireRole allows the request through only if the authenticated caller holds at least
+// one of the given roles. It reads the identity set by Auth, so it must run after Auth.
+// Unauthenticated requests get 401 so clients know to log in; authenticated callers
+// lacking a role get 403.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	required := append([]string(nil), roles...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := UserIDFromContext(r.Context()); !ok {
+				WriteError(w, r, http.StatusUnauthorized, "unauthorized", "missing or invalid access token")
+				return
+			}
+
+			granted, _ := RolesFromContext(r.Context())
+			if !hasAnyRole(granted, required) {
+				WriteError(w, r, http.StatusForbidden, "forbidden", "insufficient role for this resource")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func hasAnyRole(granted []string, required []string) bool {
+	for _, want := range required {
+		for _, have := range granted {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
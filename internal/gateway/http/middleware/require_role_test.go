@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRequireRoleDeniesCustomerOnAdminRoute(t *testing.T) {
+	handler := newAdminHandler(t, []string{"customer"})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", rr.Code)
+	}
+	assertErrorBody(t, rr, "forbidden")
+}
+
+func TestRequireRoleAllowsAdmin(t *testing.T) {
+	handler := newAdminHandler(t, []string{"customer", "admin"})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+}
+
+func TestRequireRoleDistinguishesUnauthenticatedFromForbidden(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.Handler
+		authHeader string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "missing token is rejected by Auth",
+			handler:    newAdminHandler(t, []string{"admin"}),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "unauthorized",
+		},
+		{
+			name: "route without Auth has no caller",
+			handler: RequireRole("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			authHeader: "Bearer valid-token",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "unauthorized",
+		},
+		{
+			name:       "valid token lacking the role",
+			handler:    newAdminHandler(t, nil),
+			authHeader: "Bearer valid-token",
+			wantStatus: http.StatusForbidden,
+			wantCode:   "forbidden",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin", nil)
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+			rr := httptest.NewRecorder()
+			tc.handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rr.Code)
+			}
+			assertErrorBody(t, rr, tc.wantCode)
+		})
+	}
+}
+
+func newAdminHandler(t *testing.T, roles []string) http.Handler {
+	t.Helper()
+
+	router := chi.NewRouter()
+	router.Use(RequestID)
+	router.With(
+		Auth(fakeTokenValidator{
+			validateFunc: func(ctx context.Context, accessToken string, requestID string) (string, []string, error) {
+				return "user-123", roles, nil
+			},
+		}, time.Second),
+		RequireRole("admin"),
+	).Get("/v1/admin", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return router
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+// GuardedResponseWriter tracks whether the status line has been written and turns any
+// later WriteHeader call into a logged no-op instead of a superfluous write.
+type GuardedResponseWriter struct {
+	http.ResponseWriter
+
+	logger      zerolog.Logger
+	wroteHeader bool
+	status      int
+}
+
+// WriteGuard wraps every response in a GuardedResponseWriter.
+func WriteGuard(logger zerolog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(NewGuardedResponseWriter(w, logger), r)
+		})
+	}
+}
+
+// NewGuardedResponseWriter wraps w, or returns it unchanged if it is already guarded.
+func NewGuardedResponseWriter(w http.ResponseWriter, logger zerolog.Logger) *GuardedResponseWriter {
+	if guarded, ok := w.(*GuardedResponseWriter); ok {
+		return guarded
+	}
+	return &GuardedResponseWriter{ResponseWriter: w, logger: logger}
+}
+
+// WriteHeader forwards the first final status code and ignores the rest.
+func (w *GuardedResponseWriter) WriteHeader(statusCode int) {
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if w.wroteHeader {
+		w.logger.Debug().
+			Int("status", w.status).
+			Int("ignored_status", statusCode).
+			Msg("ignored superfluous WriteHeader call")
+		return
+	}
+
+	w.wroteHeader = true
+	w.status = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the body, implicitly committing a 200 status if none was written.
+func (w *GuardedResponseWriter) Write(body []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(body)
+}
+
+// WroteHeader reports whether a final status code has been written.
+func (w *GuardedResponseWriter) WroteHeader() bool {
+	return w.wroteHeader
+}
+
+// Flush implements http.Flusher when the underlying writer supports it.
+func (w *GuardedResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *GuardedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestWriteGuardIgnoresSecondWriteHeader(t *testing.T) {
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs).Level(zerolog.DebugLevel)
+
+	handler := WriteGuard(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		// A late error path must not clobber the response already sent.
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal"})
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/me", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected first status 200 to stick, got %d", rr.Code)
+	}
+	if !strings.Contains(logs.String(), "ignored superfluous WriteHeader call") {
+		t.Fatalf("expected debug log for ignored WriteHeader, got %q", logs.String())
+	}
+	if !strings.Contains(logs.String(), `"ignored_status":500`) {
+		t.Fatalf("expected ignored status in log, got %q", logs.String())
+	}
+}
+
+func TestGuardedResponseWriterImplicitStatus(t *testing.T) {
+	rr := httptest.NewRecorder()
+	w := NewGuardedResponseWriter(rr, zerolog.Nop())
+
+	if w.WroteHeader() {
+		t.Fatal("expected no header written before first write")
+	}
+	if _, err := w.Write([]byte("ok")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !w.WroteHeader() {
+		t.Fatal("expected Write to commit the header")
+	}
+
+	w.WriteHeader(http.StatusTeapot)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected implicit 200, got %d", rr.Code)
+	}
+	if NewGuardedResponseWriter(w, zerolog.Nop()) != w {
+		t.Fatal("expected already guarded writer to be reused")
+	}
+}
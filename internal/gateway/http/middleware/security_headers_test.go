@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecurityHeadersOnErrorResponses(t *testing.T) {
+	handler := SecurityHeaders(SecurityHeadersOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, http.StatusUnauthorized, "unauthorized", "missing or invalid access token")
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/me", nil))
+
+	assertHeader(t, rr, "X-Content-Type-Options", "nosniff")
+	assertHeader(t, rr, "X-Frame-Options", "DENY")
+	assertHeader(t, rr, "Referrer-Policy", "no-referrer")
+	assertHeader(t, rr, "Strict-Transport-Security", "")
+}
+
+func TestSecurityHeadersHSTS(t *testing.T) {
+	handler := SecurityHeaders(SecurityHeadersOptions{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assertHeader(t, rr, "Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import "net/http"
+
+// RejectWhenShuttingDown answers new requests with 503 and "Connection: close" once
+// shuttingDown reports true, so requests arriving during the shutdown drain fail fast and
+// clients reconnect elsewhere instead of being served on a connection about to close.
+// Requests already past this middleware are unaffected and finish normally.
+func RejectWhenShuttingDown(shuttingDown func() bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if shuttingDown() {
+				w.Header().Set("Connection", "close")
+				WriteError(w, r, http.StatusServiceUnavailable, "shutting_down", "service is shutting down")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
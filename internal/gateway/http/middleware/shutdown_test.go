@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRejectWhenShuttingDown(t *testing.T) {
+	var shuttingDown atomic.Bool
+	handler := RejectWhenShuttingDown(shuttingDown.Load)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/me", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 before shutdown, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Connection"); got != "" {
+		t.Fatalf("expected no Connection header before shutdown, got %q", got)
+	}
+
+	shuttingDown.Store(true)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/me", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 during shutdown, got %d", rr.Code)
+	}
+	assertErrorBody(t, rr, "shutting_down")
+	assertHeader(t, rr, "Connection", "close")
+}
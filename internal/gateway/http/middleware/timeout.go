@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Timeout bounds each request with a context deadline. Downstream calls that honour the
+// request context (such as user service RPCs) are cut off at the deadline; if the handler
+// then returns without having written a response, a 504 error is written on its behalf.
+// A zero timeout disables the middleware.
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			guarded := NewGuardedResponseWriter(w, zerolog.Nop())
+			next.ServeHTTP(guarded, r.WithContext(ctx))
+
+			if !guarded.WroteHeader() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				WriteError(guarded, r, http.StatusGatewayTimeout, "timeout", "request timed out")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutWritesGatewayTimeout(t *testing.T) {
+	handler := Timeout(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/me", nil))
+
+	if rr.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status 504, got %d", rr.Code)
+	}
+	assertErrorBody(t, rr, "timeout")
+}
+
+func TestTimeoutBoundsAuthRPCContext(t *testing.T) {
+	var remaining time.Duration
+	validator := fakeTokenValidator{validateFunc: func(ctx context.Context, accessToken string, requestID string) (string, []string, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatal("expected auth rpc context to carry a deadline")
+		}
+		remaining = time.Until(deadline)
+		return "user-123", nil, nil
+	}}
+
+	// The auth RPC timeout is the tighter bound, so it wins inside the request deadline.
+	handler := Timeout(time.Minute)(Auth(validator, time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if remaining <= 0 || remaining > time.Second {
+		t.Fatalf("expected auth rpc deadline within 1s, got %v", remaining)
+	}
+}
+
+func TestTimeoutLeavesTimelyResponsesAlone(t *testing.T) {
+	handler := Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Fatal("expected request context to carry a deadline")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+)
+
+// TrustedSources restricts routes to callers whose source address falls inside one of the
+// allowed prefixes. Trusted callers need no bearer token; everyone else gets 403. An empty
+// allowlist disables the check.
+func TrustedSources(allowed []netip.Prefix) func(http.Handler) http.Handler {
+	allowedPrefixes := append([]netip.Prefix(nil), allowed...)
+
+	return func(next http.Handler) http.Handler {
+		if len(allowedPrefixes) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addr, ok := remoteAddr(r.RemoteAddr)
+			if !ok || !containsAddr(allowedPrefixes, addr) {
+				WriteError(w, r, http.StatusForbidden, "forbidden", "source address is not trusted")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func remoteAddr(value string) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(value)
+	if err != nil {
+		host = value
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
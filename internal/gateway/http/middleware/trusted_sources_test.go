@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestTrustedSourcesAllowsAllowlistedCIDR(t *testing.T) {
+	handler := newTrustedHandler([]netip.Prefix{netip.MustParsePrefix("10.244.0.0/16")})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.RemoteAddr = "10.244.3.17:41000"
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+}
+
+func TestTrustedSourcesRejectsUntrustedSource(t *testing.T) {
+	handler := newTrustedHandler([]netip.Prefix{netip.MustParsePrefix("10.244.0.0/16")})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.RemoteAddr = "203.0.113.9:41000"
+	req.Header.Set("Authorization", "Bearer some-token")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", rr.Code)
+	}
+	assertErrorBody(t, rr, "forbidden")
+}
+
+func TestTrustedSourcesEmptyAllowlistIsOpen(t *testing.T) {
+	handler := newTrustedHandler(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.RemoteAddr = "203.0.113.9:41000"
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+}
+
+func newTrustedHandler(allowed []netip.Prefix) http.Handler {
+	return TrustedSources(allowed)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+}
@@ -0,0 +1,22 @@
+package gatewayhttp
+
+import (
+	"net/http"
+
+	usersclient "github.com/ozankenangungor/go-commerce/internal/gateway/clients/users"
+	gatewaymiddleware "github.com/ozankenangungor/go-commerce/internal/gateway/http/middleware"
+)
+
+// forwardOrigin records the caller's resolved IP and User-Agent so user service calls made
+// for this request carry them for auditing.
+func forwardOrigin(trustedProxyHops int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := usersclient.WithOrigin(r.Context(), usersclient.Origin{
+				ClientIP:  gatewaymiddleware.ClientIP(r, trustedProxyHops),
+				UserAgent: r.UserAgent(),
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
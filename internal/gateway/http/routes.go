@@ -2,92 +2,270 @@ package gatewayhttp
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/ozankenangungor/go-commerce/internal/gateway/config"
 	gatewaymiddleware "github.com/ozankenangungor/go-commerce/internal/gateway/http/middleware"
+	"github.com/ozankenangungor/go-commerce/pkg/tracing"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// AccessLogFormat selects how RequestLogger renders access log entries.
+type AccessLogFormat string
+
+const (
+	// AccessLogFormatJSON emits structured zerolog entries.
+	AccessLogFormatJSON AccessLogFormat = "json"
+	// AccessLogFormatCommon emits Apache Common Log Format lines.
+	AccessLogFormatCommon AccessLogFormat = "common"
+	// AccessLogFormatCombined emits Apache Combined Log Format lines.
+	AccessLogFormatCombined AccessLogFormat = "combined"
+)
+
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogOptions configures RequestLogger.
+type AccessLogOptions struct {
+	Format AccessLogFormat
+	// Out receives Common/Combined Log Format lines.
+	Out io.Writer
+
+	// LogClientIP adds remote_addr and the client_ip resolved through TrustedProxyHops to
+	// structured entries, to diagnose proxy trust settings.
+	LogClientIP      bool
+	TrustedProxyHops int
+
+	// SuccessSampleRate is the fraction of successful (2xx) requests that are logged.
+	// Values <= 0 or >= 1 log every request. Successful probes of /healthz and /readyz are
+	// never sampled but are logged at debug instead of info; Common/Combined lines carry no
+	// level, so they still include them.
+	SuccessSampleRate float64
+
+	// SlowThreshold, when positive, logs requests that take longer at warn, regardless of
+	// sampling. Server errors are always logged at warn.
+	SlowThreshold time.Duration
+
+	// sample returns a value in [0, 1) compared against SuccessSampleRate; tests stub it.
+	sample func() float64
+}
+
+// accessLogLevel picks the level for a completed request and reports whether it should be
+// logged at all. Failures and slow requests are never sampled away.
+func (opts AccessLogOptions) accessLogLevel(path string, status int, elapsed time.Duration) (zerolog.Level, bool) {
+	if status >= http.StatusInternalServerError || (opts.SlowThreshold > 0 && elapsed > opts.SlowThreshold) {
+		return zerolog.WarnLevel, true
+	}
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return zerolog.InfoLevel, true
+	}
+	if path == "/healthz" || path == "/readyz" {
+		return zerolog.DebugLevel, true
+	}
+	if opts.SuccessSampleRate > 0 && opts.SuccessSampleRate < 1 {
+		sample := opts.sample
+		if sample == nil {
+			sample = rand.Float64
+		}
+		if sample() >= opts.SuccessSampleRate {
+			return zerolog.NoLevel, false
+		}
+	}
+	return zerolog.InfoLevel, true
+}
+
 // NewRouter creates gateway HTTP routes and middleware stack.
-func NewRouter(
-	logger zerolog.Logger,
-	validator gatewaymiddleware.TokenValidator,
-	authRPCTimeout time.Duration,
-	readyFn func() bool,
-) http.Handler {
+func NewRouter(cfg config.Config, deps Dependencies, readyFn func() bool) http.Handler {
 	if readyFn == nil {
 		readyFn = func() bool { return false }
 	}
 
+	validator := deps.TokenValidator
+	authRPCTimeout := deps.AuthRPCTimeout
+
+	accessLogWriter := deps.AccessLogWriter
+	if accessLogWriter == nil {
+		accessLogWriter = os.Stdout
+	}
+
+	requestID := gatewaymiddleware.RequestID
+	if cfg.RequestIDSuffix {
+		requestID = gatewaymiddleware.RequestIDWithSuffix
+	}
+
 	router := chi.NewRouter()
-	router.Use(gatewaymiddleware.RequestID)
+	// Config.Load validates ERROR_FORMAT; the zero value keeps the default envelope.
+	router.Use(gatewaymiddleware.WithErrorFormat(gatewaymiddleware.ErrorFormat(cfg.ErrorFormat)))
+	router.Use(requestID)
+	router.Use(gatewaymiddleware.SecurityHeaders(gatewaymiddleware.SecurityHeadersOptions{
+		HSTSMaxAge:            cfg.HSTSMaxAge,
+		HSTSIncludeSubdomains: cfg.HSTSIncludeSubdomains,
+	}))
+	router.Use(otelhttp.NewMiddleware("api-gateway"))
 	router.Use(chimiddleware.Recoverer)
-	router.Use(RequestLogger(logger))
+	router.Use(RequestLogger(deps.Logger, AccessLogOptions{
+		Format:            AccessLogFormat(cfg.AccessLogFormat),
+		Out:               accessLogWriter,
+		LogClientIP:       cfg.AccessLogClientIP,
+		TrustedProxyHops:  cfg.TrustedProxyHops,
+		SuccessSampleRate: cfg.AccessLogSampleRate,
+		SlowThreshold:     cfg.AccessLogSlowThreshold,
+	}))
+	router.Use(gatewaymiddleware.WriteGuard(deps.Logger))
+	router.Use(gatewaymiddleware.Timeout(cfg.RequestTimeout))
+	router.Use(gatewaymiddleware.CORS(gatewaymiddleware.CORSOptions{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}))
 
-	router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
-	})
+	// Internal endpoints are open by default; INTERNAL_TRUSTED_CIDRS limits them to trusted sources.
+	router.Group(func(r chi.Router) {
+		r.Use(gatewaymiddleware.TrustedSources(cfg.InternalTrustedCIDRs))
 
-	router.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
-		if !readyFn() {
-			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not_ready"})
-			return
-		}
-		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
-	})
+		r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		})
 
-	router.Route("/v1", func(r chi.Router) {
-		r.With(gatewaymiddleware.Auth(validator, authRPCTimeout)).Get("/me", func(w http.ResponseWriter, r *http.Request) {
-			userID, ok := gatewaymiddleware.UserIDFromContext(r.Context())
-			if !ok {
-				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		r.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
+			if !readyFn() {
+				gatewaymiddleware.WriteError(w, r, http.StatusServiceUnavailable, "not_ready", "service is not ready")
 				return
 			}
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+		})
+	})
 
-			roles, ok := gatewaymiddleware.RolesFromContext(r.Context())
-			if !ok {
-				roles = []string{}
-			}
+	router.Route("/v1", func(r chi.Router) {
+		// Probes stay reachable during shutdown so /readyz keeps reporting not_ready.
+		if cfg.ShutdownRejectNew && deps.ShuttingDown != nil {
+			r.Use(gatewaymiddleware.RejectWhenShuttingDown(deps.ShuttingDown))
+		}
+		r.Use(gatewaymiddleware.RateLimit(gatewaymiddleware.RateLimitOptions{
+			RequestsPerSecond: cfg.RateLimitRPS,
+			Burst:             cfg.RateLimitBurst,
+			TrustedProxyHops:  cfg.TrustedProxyHops,
+			EmitHeaders:       cfg.RateLimitHeaders,
+		}))
+		r.Use(honorNoCache)
+		r.Use(forwardOrigin(cfg.TrustedProxyHops))
 
-			writeJSON(w, http.StatusOK, map[string]any{
-				"user_id": userID,
-				"roles":   roles,
-			})
-		})
+		// Credential endpoints are public; Auth applies only to the routes that need it.
+		if deps.AuthClient != nil {
+			auth := authHandlers{client: deps.AuthClient, rpcTimeout: authRPCTimeout}
+			r.Post("/auth/register", auth.register)
+			r.Post("/auth/login", auth.login)
+			r.Post("/auth/refresh", auth.refresh)
+		}
+
+		var profiles ProfileClient
+		if cfg.MeIncludeProfile {
+			profiles = deps.ProfileClient
+		}
+		r.With(gatewaymiddleware.Auth(validator, authRPCTimeout)).Get("/me", meHandler(profiles, authRPCTimeout))
 	})
 
 	return router
 }
 
-// RequestLogger logs HTTP requests with structured fields.
-func RequestLogger(logger zerolog.Logger) func(http.Handler) http.Handler {
+// RequestLogger logs HTTP requests with structured fields, or as Common/Combined
+// Log Format lines written to opts.Out when one of those formats is selected.
+func RequestLogger(logger zerolog.Logger, opts AccessLogOptions) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			wrapped := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ctx, authUserID := gatewaymiddleware.WithUserIDSlot(r.Context())
 
-			next.ServeHTTP(wrapped, r)
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
 
 			status := wrapped.Status()
 			if status == 0 {
 				status = http.StatusOK
 			}
 
-			logger.Info().
-				Str("request_id", gatewaymiddleware.RequestIDFromContext(r.Context())).
+			elapsed := time.Since(start)
+			level, ok := opts.accessLogLevel(r.URL.Path, status, elapsed)
+			if !ok {
+				return
+			}
+
+			switch opts.Format {
+			case AccessLogFormatCommon, AccessLogFormatCombined:
+				line := formatAccessLogLine(opts.Format, r, authUserID(), status, wrapped.BytesWritten(), start)
+				if _, err := io.WriteString(opts.Out, line+"\n"); err != nil {
+					logger.Error().Err(err).Msg("failed to write access log line")
+				}
+				return
+			}
+
+			event := logger.WithLevel(level).
+				Str("request_id", gatewaymiddleware.RequestIDFromContext(r.Context()))
+			if traceID := tracing.TraceID(r.Context()); traceID != "" {
+				event = event.Str("trace_id", traceID)
+			}
+			if opts.LogClientIP {
+				event = event.
+					Str("remote_addr", r.RemoteAddr).
+					Str("client_ip", gatewaymiddleware.ClientIP(r, opts.TrustedProxyHops))
+			}
+			event.
 				Str("method", r.Method).
 				Str("path", r.URL.Path).
 				Int("status", status).
 				Int("bytes", wrapped.BytesWritten()).
-				Dur("duration", time.Since(start)).
+				Dur("duration", elapsed).
 				Msg("http_request")
 		})
 	}
 }
 
+func formatAccessLogLine(format AccessLogFormat, r *http.Request, userID string, status int, bytes int, at time.Time) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	size := "-"
+	if bytes > 0 {
+		size = fmt.Sprintf("%d", bytes)
+	}
+
+	line := fmt.Sprintf(
+		"%s - %s [%s] \"%s %s %s\" %d %s",
+		clfField(host),
+		clfField(userID),
+		at.Format(clfTimeLayout),
+		r.Method,
+		r.URL.RequestURI(),
+		r.Proto,
+		status,
+		size,
+	)
+
+	if format == AccessLogFormatCombined {
+		line += fmt.Sprintf(" %q %q", clfField(r.Referer()), clfField(r.UserAgent()))
+	}
+	return line
+}
+
+func clfField(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
 func writeJSON(w http.ResponseWriter, statusCode int, payload any) {
 	body, err := json.Marshal(payload)
 	if err != nil {
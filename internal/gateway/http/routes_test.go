@@ -0,0 +1,225 @@
+package gatewayhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ozankenangungor/go-commerce/internal/gateway/config"
+	gatewaymiddleware "github.com/ozankenangungor/go-commerce/internal/gateway/http/middleware"
+	"github.com/ozankenangungor/go-commerce/pkg/tracing"
+	"github.com/rs/zerolog"
+)
+
+type stubTokenValidator struct {
+	userID string
+	roles  []string
+}
+
+func (s stubTokenValidator) ValidateAccessToken(ctx context.Context, accessToken string, requestID string) (string, []string, error) {
+	return s.userID, s.roles, nil
+}
+
+func TestRequestLoggerCommonLogFormat(t *testing.T) {
+	var out bytes.Buffer
+	handler := newAccessLogHandler(AccessLogFormatCommon, &out)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me?verbose=1", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("Authorization", "Bearer token")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	pattern := regexp.MustCompile(`^203\.0\.113\.7 - user-123 \[\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /v1/me\?verbose=1 HTTP/1\.1" 200 \d+\n$`)
+	if !pattern.MatchString(out.String()) {
+		t.Fatalf("unexpected common log line: %q", out.String())
+	}
+}
+
+func TestRequestLoggerCombinedLogFormat(t *testing.T) {
+	var out bytes.Buffer
+	handler := newAccessLogHandler(AccessLogFormatCombined, &out)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("Referer", "https://shop.example.com/")
+	req.Header.Set("User-Agent", "curl/8.5.0")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	pattern := regexp.MustCompile(`^203\.0\.113\.7 - - \[[^\]]+\] "GET /healthz HTTP/1\.1" 200 \d+ "https://shop\.example\.com/" "curl/8\.5\.0"\n$`)
+	if !pattern.MatchString(out.String()) {
+		t.Fatalf("unexpected combined log line: %q", out.String())
+	}
+}
+
+func TestFormatAccessLogLineEmptyBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/login", nil)
+	req.RemoteAddr = "198.51.100.1:4000"
+	at := time.Date(2026, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	got := formatAccessLogLine(AccessLogFormatCommon, req, "", http.StatusNoContent, 0, at)
+	want := `198.51.100.1 - - [04/Mar/2026:05:06:07 +0000] "POST /v1/auth/login HTTP/1.1" 204 -`
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRequestLoggerJSONDefault(t *testing.T) {
+	var logs bytes.Buffer
+	var out bytes.Buffer
+	router := chi.NewRouter()
+	router.Use(RequestLogger(zerolog.New(&logs), AccessLogOptions{Format: AccessLogFormatJSON, Out: &out}))
+	router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if out.Len() != 0 {
+		t.Fatalf("expected no access log line in json mode, got %q", out.String())
+	}
+	if !strings.Contains(logs.String(), `"message":"http_request"`) {
+		t.Fatalf("expected structured http_request entry, got %q", logs.String())
+	}
+}
+
+func TestAccessLogLevel(t *testing.T) {
+	sampled := AccessLogOptions{SuccessSampleRate: 0.1, SlowThreshold: time.Second, sample: func() float64 { return 0.5 }}
+
+	tests := []struct {
+		name      string
+		opts      AccessLogOptions
+		path      string
+		status    int
+		elapsed   time.Duration
+		wantLevel zerolog.Level
+		wantLog   bool
+	}{
+		{name: "success logged without sampling", opts: AccessLogOptions{}, path: "/v1/me", status: http.StatusOK, wantLevel: zerolog.InfoLevel, wantLog: true},
+		{name: "success sampled away", opts: sampled, path: "/v1/me", status: http.StatusOK, wantLog: false},
+		{name: "redirect never sampled", opts: AccessLogOptions{SuccessSampleRate: 0.1, sample: func() float64 { return 0.99 }}, path: "/v1/me", status: http.StatusFound, wantLevel: zerolog.InfoLevel, wantLog: true},
+		{name: "client error never sampled", opts: sampled, path: "/v1/me", status: http.StatusUnauthorized, wantLevel: zerolog.InfoLevel, wantLog: true},
+		{name: "server error at warn", opts: sampled, path: "/v1/me", status: http.StatusBadGateway, wantLevel: zerolog.WarnLevel, wantLog: true},
+		{name: "slow success at warn", opts: sampled, path: "/v1/me", status: http.StatusOK, elapsed: 2 * time.Second, wantLevel: zerolog.WarnLevel, wantLog: true},
+		{name: "probe downgraded to debug", opts: sampled, path: "/healthz", status: http.StatusOK, wantLevel: zerolog.DebugLevel, wantLog: true},
+		{name: "failing probe at warn", opts: sampled, path: "/readyz", status: http.StatusServiceUnavailable, wantLevel: zerolog.WarnLevel, wantLog: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			level, ok := tc.opts.accessLogLevel(tc.path, tc.status, tc.elapsed)
+			if ok != tc.wantLog {
+				t.Fatalf("expected logged=%v, got %v", tc.wantLog, ok)
+			}
+			if ok && level != tc.wantLevel {
+				t.Fatalf("expected level %v, got %v", tc.wantLevel, level)
+			}
+		})
+	}
+}
+
+func TestRequestLoggerProbesAtDebug(t *testing.T) {
+	var logs bytes.Buffer
+	router := chi.NewRouter()
+	router.Use(RequestLogger(zerolog.New(&logs).Level(zerolog.InfoLevel), AccessLogOptions{Format: AccessLogFormatJSON}))
+	router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if logs.Len() != 0 {
+		t.Fatalf("expected probe entry to be filtered at info level, got %q", logs.String())
+	}
+}
+
+func TestRouterErrorFormatProblemJSON(t *testing.T) {
+	router := NewRouter(config.Config{AccessLogFormat: "json", ErrorFormat: "problemjson"}, Dependencies{
+		Logger:         zerolog.Nop(),
+		TokenValidator: stubTokenValidator{userID: "user-123"},
+		AuthRPCTimeout: time.Second,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.Header.Set(gatewaymiddleware.RequestIDHeader, "req-abc")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Fatalf("expected application/problem+json, got %q", got)
+	}
+	for _, want := range []string{`"status":401`, `"instance":"req-abc"`, `"code":"unauthorized"`} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Fatalf("expected body to contain %s, got %s", want, rr.Body.String())
+		}
+	}
+}
+
+func TestRequestLoggerClientIPFields(t *testing.T) {
+	var logs bytes.Buffer
+	router := NewRouter(config.Config{AccessLogFormat: "json", AccessLogClientIP: true, TrustedProxyHops: 1}, Dependencies{
+		Logger:         zerolog.New(&logs),
+		TokenValidator: stubTokenValidator{userID: "user-123"},
+		AuthRPCTimeout: time.Second,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.RemoteAddr = "10.0.0.1:4000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("decode access log entry %q: %v", logs.String(), err)
+	}
+	if entry["remote_addr"] != "10.0.0.1:4000" {
+		t.Fatalf("expected remote_addr 10.0.0.1:4000, got %v", entry["remote_addr"])
+	}
+	if entry["client_ip"] != "203.0.113.7" {
+		t.Fatalf("expected client_ip 203.0.113.7, got %v", entry["client_ip"])
+	}
+}
+
+func TestRouterLogsTraceIDFromTraceparent(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), tracing.Options{ServiceName: "test"}); err != nil {
+		t.Fatalf("setup tracing: %v", err)
+	}
+
+	var logs bytes.Buffer
+	router := NewRouter(config.Config{AccessLogFormat: "json"}, Dependencies{
+		Logger:         zerolog.New(&logs),
+		TokenValidator: stubTokenValidator{userID: "user-123"},
+		AuthRPCTimeout: time.Second,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !strings.Contains(logs.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`) {
+		t.Fatalf("expected trace id in access log, got %q", logs.String())
+	}
+}
+
+func newAccessLogHandler(format AccessLogFormat, out *bytes.Buffer) http.Handler {
+	router := chi.NewRouter()
+	router.Use(gatewaymiddleware.RequestID)
+	router.Use(RequestLogger(zerolog.Nop(), AccessLogOptions{Format: format, Out: out}))
+	router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	router.With(gatewaymiddleware.Auth(stubTokenValidator{userID: "user-123"}, time.Second)).
+		Get("/v1/me", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		})
+	return router
+}
@@ -3,6 +3,7 @@ package gatewayhttp
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -16,23 +17,37 @@ import (
 type Dependencies struct {
 	Logger         zerolog.Logger
 	TokenValidator gatewaymiddleware.TokenValidator
+	AuthClient     AuthClient
+	ProfileClient  ProfileClient
 	AuthRPCTimeout time.Duration
+
+	// ShuttingDown reports whether shutdown has begun. NewServer wires it to the server's
+	// own state; when set and SHUTDOWN_REJECT_NEW_REQUESTS is enabled, new API requests
+	// are rejected with 503.
+	ShuttingDown func() bool
+
+	// AccessLogWriter receives Common/Combined Log Format lines. Defaults to stdout.
+	AccessLogWriter io.Writer
 }
 
 // Server encapsulates the API gateway HTTP server.
 type Server struct {
-	httpServer *http.Server
-	logger     zerolog.Logger
-	ready      atomic.Bool
+	httpServer   *http.Server
+	logger       zerolog.Logger
+	ready        atomic.Bool
+	shuttingDown atomic.Bool
+	drainDelay   time.Duration
 }
 
 // NewServer builds a new API gateway HTTP server.
 func NewServer(cfg config.Config, deps Dependencies) *Server {
 	srv := &Server{
-		logger: deps.Logger,
+		logger:     deps.Logger,
+		drainDelay: cfg.ShutdownDrainDelay,
 	}
 
-	router := NewRouter(deps.Logger, deps.TokenValidator, deps.AuthRPCTimeout, srv.Ready)
+	deps.ShuttingDown = srv.ShuttingDown
+	router := NewRouter(cfg, deps, srv.Ready)
 	srv.httpServer = &http.Server{
 		Addr:              cfg.GatewayHTTPAddr,
 		Handler:           router,
@@ -57,12 +72,32 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// Shutdown gracefully stops the server.
+// Shutdown gracefully stops the server. Readiness flips to false first; the server then
+// keeps serving for the configured drain delay so load balancers see /readyz fail and
+// deregister it before connections are closed. If new requests are rejected during
+// shutdown, that starts at the same moment readiness flips.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
 	s.ready.Store(false)
+
+	if s.drainDelay > 0 {
+		s.logger.Info().Dur("drain_delay", s.drainDelay).Msg("draining before shutdown")
+		timer := time.NewTimer(s.drainDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
 	return s.httpServer.Shutdown(ctx)
 }
 
+// ShuttingDown reports whether Shutdown has been called.
+func (s *Server) ShuttingDown() bool {
+	return s.shuttingDown.Load()
+}
+
 // Ready returns readiness state.
 func (s *Server) Ready() bool {
 	return s.ready.Load()
@@ -0,0 +1,124 @@
+package gatewayhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ozankenangungor/go-commerce/internal/gateway/config"
+	"github.com/rs/zerolog"
+)
+
+func TestShutdownDrainsBeforeClosing(t *testing.T) {
+	const drainDelay = 50 * time.Millisecond
+	srv := NewServer(config.Config{GatewayHTTPAddr: "127.0.0.1:0", ShutdownDrainDelay: drainDelay}, Dependencies{
+		Logger:         zerolog.Nop(),
+		TokenValidator: stubTokenValidator{},
+		AuthRPCTimeout: time.Second,
+	})
+	srv.ready.Store(true)
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Shutdown(context.Background())
+	}()
+
+	// Readiness must drop as soon as shutdown begins, before the drain delay elapses.
+	deadline := time.Now().Add(drainDelay / 2)
+	for srv.Ready() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if srv.Ready() {
+		t.Fatal("expected readiness to flip to false at the start of shutdown")
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < drainDelay {
+		t.Fatalf("expected shutdown to wait for the %v drain delay, returned after %v", drainDelay, elapsed)
+	}
+}
+
+func TestShutdownDrainRespectsContextDeadline(t *testing.T) {
+	srv := NewServer(config.Config{GatewayHTTPAddr: "127.0.0.1:0", ShutdownDrainDelay: time.Hour}, Dependencies{
+		Logger:         zerolog.Nop(),
+		TokenValidator: stubTokenValidator{},
+		AuthRPCTimeout: time.Second,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		_ = srv.Shutdown(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected shutdown to stop draining when its context expires")
+	}
+}
+
+func TestShutdownRejectsNewRequests(t *testing.T) {
+	srv := NewServer(config.Config{
+		GatewayHTTPAddr:    "127.0.0.1:0",
+		ShutdownDrainDelay: time.Hour,
+		ShutdownRejectNew:  true,
+	}, Dependencies{
+		Logger:         zerolog.Nop(),
+		TokenValidator: stubTokenValidator{userID: "user-123"},
+		AuthRPCTimeout: time.Second,
+	})
+	srv.ready.Store(true)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		rr := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := serve("/v1/me"); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 before shutdown, got %d", rr.Code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = srv.Shutdown(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !srv.ShuttingDown() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	rr := serve("/v1/me")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 after shutdown began, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"code":"shutting_down"`) {
+		t.Fatalf("expected shutting_down error, got %s", rr.Body.String())
+	}
+	if got := rr.Header().Get("Connection"); got != "close" {
+		t.Fatalf("expected Connection: close, got %q", got)
+	}
+
+	if rr := serve("/readyz"); rr.Code != http.StatusServiceUnavailable || !strings.Contains(rr.Body.String(), `"code":"not_ready"`) {
+		t.Fatalf("expected /readyz to report not_ready, got %d %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,50 @@
+package useradmin
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	userdb "github.com/ozankenangungor/go-commerce/internal/user/db"
+	"github.com/ozankenangungor/go-commerce/internal/user/httpserver"
+	"github.com/rs/zerolog"
+)
+
+// NewServer builds the user service's internal admin listener. It exposes operational
+// endpoints only and should not be reachable from outside the cluster. /debug/dbstats
+// (aliased as /debug/pool) reports connection pool statistics. Every route requires
+// "Authorization: Bearer <token>".
+func NewServer(addr string, logger zerolog.Logger, pool *pgxpool.Pool, token string) (*httpserver.Server, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db pool is required")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("admin token is required")
+	}
+
+	return httpserver.New("admin", addr, logger, requireToken(token, newMux(pool)))
+}
+
+func newMux(pool *pgxpool.Pool) *http.ServeMux {
+	stats := userdb.StatsHandler(pool)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/dbstats", stats)
+	mux.Handle("GET /debug/pool", stats)
+	return mux
+}
+
+// requireToken is the admin gate: requests without the configured bearer token get 401.
+func requireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadInvalidMaxConns(t *testing.T) {
@@ -16,11 +18,22 @@ func TestLoadInvalidMaxConns(t *testing.T) {
 
 func TestLoadDefaults(t *testing.T) {
 	keys := []string{
+		"CONFIG_FILE",
 		"USER_SERVICE_GRPC_ADDR",
 		"USER_DB_DSN",
 		"USER_DB_MAX_CONNS",
 		"LOG_LEVEL",
+		"LOG_FORMAT",
 		"USER_DB_MIGRATIONS_PATH",
+		"USER_DB_MIGRATIONS_EMBEDDED",
+		"USER_GRPC_MAX_CONCURRENT_STREAMS",
+		"USER_DB_CONNECT_TIMEOUT",
+		"USER_DB_MAX_CONN_LIFETIME",
+		"USER_DB_MAX_CONN_IDLE_TIME",
+		"USER_DB_HEALTHCHECK_PERIOD",
+		"USER_DB_CONNECT_MAX_ATTEMPTS",
+		"USER_DB_CONNECT_BACKOFF",
+		"USER_DB_CONNECT_MAX_BACKOFF",
 	}
 
 	for _, key := range keys {
@@ -41,4 +54,98 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.UserDBMaxConns != 10 {
 		t.Fatalf("expected default max conns 10, got %d", cfg.UserDBMaxConns)
 	}
+	if cfg.GRPCMaxConcurrentStreams != 100 {
+		t.Fatalf("expected default max concurrent streams 100, got %d", cfg.GRPCMaxConcurrentStreams)
+	}
+	if cfg.LogFormat != "json" {
+		t.Fatalf("expected default log format json, got %q", cfg.LogFormat)
+	}
+	if cfg.DBConnectTimeout != 30*time.Second {
+		t.Fatalf("expected default db connect timeout 30s, got %v", cfg.DBConnectTimeout)
+	}
+}
+
+func TestLoadRejectsUnknownLogFormat(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "pretty")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for LOG_FORMAT=pretty")
+	}
+}
+
+func TestLoadRequiresAdminTokenWithAdminAddr(t *testing.T) {
+	t.Setenv("USER_SERVICE_ADMIN_ADDR", ":9091")
+	t.Setenv("USER_SERVICE_ADMIN_TOKEN", "")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for USER_SERVICE_ADMIN_ADDR without USER_SERVICE_ADMIN_TOKEN")
+	}
+}
+
+func TestLoadRejectsNegativePoolLifetime(t *testing.T) {
+	t.Setenv("USER_DB_MAX_CONN_LIFETIME", "-1m")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for negative USER_DB_MAX_CONN_LIFETIME")
+	}
+}
+
+func TestLoadRejectsMaxBackoffBelowInitialBackoff(t *testing.T) {
+	t.Setenv("USER_DB_CONNECT_BACKOFF", "2s")
+	t.Setenv("USER_DB_CONNECT_MAX_BACKOFF", "1s")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for USER_DB_CONNECT_MAX_BACKOFF below USER_DB_CONNECT_BACKOFF")
+	}
+}
+
+func TestLoadRejectsNonPositiveMaxConcurrentStreams(t *testing.T) {
+	t.Setenv("USER_GRPC_MAX_CONCURRENT_STREAMS", "0")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for USER_GRPC_MAX_CONCURRENT_STREAMS=0")
+	}
+}
+
+func TestLoadRejectsMaxConcurrentStreamsAboveUint32(t *testing.T) {
+	t.Setenv("USER_GRPC_MAX_CONCURRENT_STREAMS", "4294967296")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for USER_GRPC_MAX_CONCURRENT_STREAMS above the uint32 range")
+	}
+}
+
+func TestLoadMergesConfigFileUnderEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user-service.yaml")
+	content := "USER_DB_MAX_CONNS: 25\nLOG_LEVEL: debug\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("USER_DB_MAX_CONNS", "")
+	t.Setenv("LOG_LEVEL", "warn")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.UserDBMaxConns != 25 {
+		t.Fatalf("expected max conns 25 from file, got %d", cfg.UserDBMaxConns)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Fatalf("expected env LOG_LEVEL to override file, got %q", cfg.LogLevel)
+	}
+}
+
+func TestLoadValidatesConfigFileValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user-service.yaml")
+	if err := os.WriteFile(path, []byte("USER_DB_MAX_CONNS: 0\n"), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("USER_DB_MAX_CONNS", "")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected validation error for USER_DB_MAX_CONNS=0 from config file")
+	}
 }
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ExpectedMigrationsChecksum is the migrations checksum the build was cut with, injected via
+// -ldflags "-X github.com/ozankenangungor/go-commerce/internal/user/db.ExpectedMigrationsChecksum=<hex>"
+// (see `make migrations-checksum`). Empty disables the startup check.
+var ExpectedMigrationsChecksum string
+
+// MigrationsChecksum returns a SHA-256 digest over the migration files in dir. The digest is
+// taken over sha256sum-style "<file hash>  <name>" lines in byte order of name, so it can be
+// reproduced with `sha256sum *.sql | sha256sum` under LC_ALL=C.
+func MigrationsChecksum(dir string) (string, error) {
+	return migrationsChecksum(os.DirFS(dir))
+}
+
+func migrationsChecksum(fsys fs.FS) (string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return "", fmt.Errorf("read migrations path: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && migrationFilePattern.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+
+	digest := sha256.New()
+	for _, name := range names {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return "", fmt.Errorf("read migration %s: %w", name, err)
+		}
+		fileSum := sha256.Sum256(content)
+		fmt.Fprintf(digest, "%s  %s\n", hex.EncodeToString(fileSum[:]), name)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// VerifyMigrationsChecksum fails when the migrations in dir do not hash to expected.
+func VerifyMigrationsChecksum(dir string, expected string) error {
+	actual, err := MigrationsChecksum(dir)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("migrations checksum mismatch at %s: expected %s, got %s", dir, expected, actual)
+	}
+	return nil
+}
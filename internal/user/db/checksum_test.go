@@ -0,0 +1,65 @@
+package db
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyMigrationsChecksumDetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	writeMigration(t, dir, "000001_init.up.sql", "CREATE TABLE users (id TEXT PRIMARY KEY);\n")
+	writeMigration(t, dir, "000001_init.down.sql", "DROP TABLE users;\n")
+
+	expected, err := MigrationsChecksum(dir)
+	if err != nil {
+		t.Fatalf("checksum: %v", err)
+	}
+	if err := VerifyMigrationsChecksum(dir, expected); err != nil {
+		t.Fatalf("expected untouched migrations to verify: %v", err)
+	}
+
+	writeMigration(t, dir, "000001_init.up.sql", "CREATE TABLE users (id TEXT PRIMARY KEY, admin BOOLEAN);\n")
+
+	err = VerifyMigrationsChecksum(dir, expected)
+	if err == nil {
+		t.Fatal("expected tampered migration to fail verification")
+	}
+	if !strings.Contains(err.Error(), "migrations checksum mismatch") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMigrationsChecksumMatchesSha256sum(t *testing.T) {
+	if _, err := exec.LookPath("sha256sum"); err != nil {
+		t.Skip("sha256sum not available")
+	}
+
+	dir := t.TempDir()
+	writeMigration(t, dir, "000001_init.up.sql", "CREATE TABLE users (id TEXT PRIMARY KEY);\n")
+	writeMigration(t, dir, "000001_init.down.sql", "DROP TABLE users;\n")
+
+	cmd := exec.Command("sh", "-c", "export LC_ALL=C; sha256sum *.sql | sha256sum")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("run sha256sum: %v", err)
+	}
+
+	got, err := MigrationsChecksum(dir)
+	if err != nil {
+		t.Fatalf("checksum: %v", err)
+	}
+	if want := strings.Fields(string(out))[0]; got != want {
+		t.Fatalf("expected checksum %s to match make target output %s", got, want)
+	}
+}
+
+func writeMigration(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("write migration %s: %v", name, err)
+	}
+}
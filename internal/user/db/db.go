@@ -3,28 +3,97 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
 )
 
-// NewPool creates and verifies a Postgres connection pool.
-func NewPool(ctx context.Context, dsn string, maxConns int32) (*pgxpool.Pool, error) {
+// PoolOptions sizes the pool and bounds connection lifetimes. Zero durations keep the
+// pgxpool defaults (1h lifetime, 30m idle time, 1m health check period).
+type PoolOptions struct {
+	MaxConns int32
+	// MaxConnLifetime and MaxConnIdleTime recycle connections so the pool reconnects to a
+	// replaced database instead of holding stale connections.
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+}
+
+// ConnectRetry controls how NewPool waits for the database to become reachable at startup.
+// The total wait is bounded by the context passed to NewPool.
+type ConnectRetry struct {
+	// MaxAttempts caps the number of pings; zero keeps retrying until the context is done.
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failed ping; it doubles up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// NewPool creates and verifies a Postgres connection pool, retrying the initial ping so the
+// service can start before the database is accepting connections.
+func NewPool(ctx context.Context, dsn string, opts PoolOptions, retry ConnectRetry, logger zerolog.Logger) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("parse db dsn: %w", err)
 	}
 
-	cfg.MaxConns = maxConns
+	applyPoolOptions(cfg, opts)
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("create db pool: %w", err)
 	}
 
-	if err := pool.Ping(ctx); err != nil {
+	if err := pingWithRetry(ctx, pool.Ping, retry, logger); err != nil {
 		pool.Close()
-		return nil, fmt.Errorf("ping db: %w", err)
+		return nil, err
 	}
 
 	return pool, nil
 }
+
+func applyPoolOptions(cfg *pgxpool.Config, opts PoolOptions) {
+	cfg.MaxConns = opts.MaxConns
+	if opts.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = opts.MaxConnLifetime
+	}
+	if opts.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
+	if opts.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = opts.HealthCheckPeriod
+	}
+}
+
+func pingWithRetry(ctx context.Context, ping func(context.Context) error, retry ConnectRetry, logger zerolog.Logger) error {
+	backoff := retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if retry.MaxAttempts > 0 && attempt >= retry.MaxAttempts {
+			return fmt.Errorf("ping db after %d attempts: %w", attempt, err)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("ping db after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn().Err(err).Int("attempt", attempt).Dur("backoff", backoff).Msg("database not reachable, retrying")
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("ping db after %d attempts: %w", attempt, err)
+		}
+
+		backoff *= 2
+		if retry.MaxBackoff > 0 && backoff > retry.MaxBackoff {
+			backoff = retry.MaxBackoff
+		}
+	}
+}
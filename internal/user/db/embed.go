@@ -0,0 +1,20 @@
+package db
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// Migrations returns the migration files compiled into the binary, rooted at the
+// migrations directory so it can be passed straight to RunMigrationsFS.
+func Migrations() fs.FS {
+	sub, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		// fs.Sub only fails on an invalid path, and "migrations" is a constant.
+		panic(err)
+	}
+	return sub
+}
@@ -3,51 +3,174 @@ package db
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"regexp"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
+// migrationFilePattern matches golang-migrate's file source naming: <version>_<title>.<up|down>.<ext>.
+var migrationFilePattern = regexp.MustCompile(`^[0-9]+_.*\.(up|down)\..+$`)
+
+// AllMigrations passed to MigrateDown rolls back every applied migration.
+const AllMigrations = -1
+
 // RunMigrations applies SQL migrations from the provided directory.
 func RunMigrations(dsn string, migrationsPath string) error {
+	migrator, err := newMigrator(dsn, migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	return closeMigrator(migrator, migrateUp(migrator))
+}
+
+// RunMigrationsFS applies SQL migrations read from the root of fsys, such as the ones
+// returned by Migrations.
+func RunMigrationsFS(dsn string, fsys fs.FS) error {
 	if dsn == "" {
 		return fmt.Errorf("db dsn is required")
 	}
+	if fsys == nil {
+		return fmt.Errorf("migrations filesystem is required")
+	}
+
+	found, err := hasMigrationFiles(fsys)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no migration files found in migrations filesystem")
+	}
+
+	source, err := iofs.New(fsys, ".")
+	if err != nil {
+		return fmt.Errorf("open migrations filesystem: %w", err)
+	}
+	migrator, err := migrate.NewWithSourceInstance("iofs", source, dsn)
+	if err != nil {
+		_ = source.Close()
+		return fmt.Errorf("create migrator: %w", err)
+	}
+
+	return closeMigrator(migrator, migrateUp(migrator))
+}
+
+// MigrationVersion reports the currently applied migration version and whether the last
+// migration failed part-way (dirty). A database with no migrations applied reports 0.
+func MigrationVersion(dsn string, migrationsPath string) (uint, bool, error) {
+	migrator, err := newMigrator(dsn, migrationsPath)
+	if err != nil {
+		return 0, false, err
+	}
+
+	version, dirty, versionErr := migrator.Version()
+	if errors.Is(versionErr, migrate.ErrNilVersion) {
+		version, dirty, versionErr = 0, false, nil
+	}
+	if versionErr != nil {
+		versionErr = fmt.Errorf("read migration version: %w", versionErr)
+	}
+	if err := closeMigrator(migrator, versionErr); err != nil {
+		return 0, false, err
+	}
+	return version, dirty, nil
+}
+
+// MigrateDown rolls back the given number of applied migrations, or all of them with
+// AllMigrations. Rolling back when nothing is applied is not an error.
+func MigrateDown(dsn string, migrationsPath string, steps int) error {
+	if steps <= 0 && steps != AllMigrations {
+		return fmt.Errorf("migration steps must be > 0")
+	}
+
+	migrator, err := newMigrator(dsn, migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	var downErr error
+	if steps == AllMigrations {
+		downErr = migrator.Down()
+	} else {
+		downErr = migrator.Steps(-steps)
+	}
+	if errors.Is(downErr, migrate.ErrNoChange) || errors.Is(downErr, migrate.ErrNilVersion) {
+		downErr = nil
+	}
+	if downErr != nil {
+		downErr = fmt.Errorf("roll back migrations: %w", downErr)
+	}
+	return closeMigrator(migrator, downErr)
+}
+
+func newMigrator(dsn string, migrationsPath string) (*migrate.Migrate, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("db dsn is required")
+	}
 	if migrationsPath == "" {
-		return fmt.Errorf("migrations path is required")
+		return nil, fmt.Errorf("migrations path is required")
 	}
 
 	absolutePath, err := filepath.Abs(migrationsPath)
 	if err != nil {
-		return fmt.Errorf("resolve migrations path: %w", err)
+		return nil, fmt.Errorf("resolve migrations path: %w", err)
+	}
+
+	// An empty source makes Up a silent no-op that leaves the schema unapplied.
+	found, err := hasMigrationFiles(os.DirFS(absolutePath))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no migration files found at %s", absolutePath)
 	}
 
 	migrator, err := migrate.New("file://"+absolutePath, dsn)
 	if err != nil {
-		return fmt.Errorf("create migrator: %w", err)
+		return nil, fmt.Errorf("create migrator: %w", err)
 	}
+	return migrator, nil
+}
 
-	upErr := migrator.Up()
-	if upErr != nil && !errors.Is(upErr, migrate.ErrNoChange) {
-		sourceErr, dbErr := migrator.Close()
-		if sourceErr != nil {
-			return fmt.Errorf("close migrator source after migration error: %w", sourceErr)
-		}
-		if dbErr != nil {
-			return fmt.Errorf("close migrator db after migration error: %w", dbErr)
-		}
-		return fmt.Errorf("run migrations: %w", upErr)
+// migrateUp applies all pending migrations; an up-to-date schema is not an error.
+func migrateUp(migrator *migrate.Migrate) error {
+	err := migrator.Up()
+	if err == nil || errors.Is(err, migrate.ErrNoChange) {
+		return nil
 	}
+	return fmt.Errorf("run migrations: %w", err)
+}
 
+// closeMigrator closes migrator, reporting opErr ahead of any close failure.
+func closeMigrator(migrator *migrate.Migrate, opErr error) error {
 	sourceErr, dbErr := migrator.Close()
+	if opErr != nil {
+		return opErr
+	}
 	if sourceErr != nil {
 		return fmt.Errorf("close migrator source: %w", sourceErr)
 	}
 	if dbErr != nil {
 		return fmt.Errorf("close migrator db: %w", dbErr)
 	}
-
 	return nil
 }
+
+func hasMigrationFiles(fsys fs.FS) (bool, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return false, fmt.Errorf("read migrations path: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && migrationFilePattern.MatchString(entry.Name()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolStats is a JSON-friendly snapshot of pgxpool statistics.
+type PoolStats struct {
+	TotalConns              int32 `json:"total_conns"`
+	IdleConns               int32 `json:"idle_conns"`
+	AcquiredConns           int32 `json:"acquired_conns"`
+	ConstructingConns       int32 `json:"constructing_conns"`
+	MaxConns                int32 `json:"max_conns"`
+	AcquireCount            int64 `json:"acquire_count"`
+	EmptyAcquireCount       int64 `json:"empty_acquire_count"`
+	CanceledAcquireCount    int64 `json:"canceled_acquire_count"`
+	AcquireDurationMillis   int64 `json:"acquire_duration_ms"`
+	EmptyAcquireWaitMillis  int64 `json:"empty_acquire_wait_ms"`
+	NewConnsCount           int64 `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64 `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64 `json:"max_idle_destroy_count"`
+}
+
+// Stats reads a point-in-time snapshot of the pool statistics.
+func Stats(pool *pgxpool.Pool) PoolStats {
+	stat := pool.Stat()
+	return PoolStats{
+		TotalConns:              stat.TotalConns(),
+		IdleConns:               stat.IdleConns(),
+		AcquiredConns:           stat.AcquiredConns(),
+		ConstructingConns:       stat.ConstructingConns(),
+		MaxConns:                stat.MaxConns(),
+		AcquireCount:            stat.AcquireCount(),
+		EmptyAcquireCount:       stat.EmptyAcquireCount(),
+		CanceledAcquireCount:    stat.CanceledAcquireCount(),
+		AcquireDurationMillis:   stat.AcquireDuration().Milliseconds(),
+		EmptyAcquireWaitMillis:  stat.EmptyAcquireWaitTime().Milliseconds(),
+		NewConnsCount:           stat.NewConnsCount(),
+		MaxLifetimeDestroyCount: stat.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     stat.MaxIdleDestroyCount(),
+	}
+}
+
+// StatsHandler serves the current pool statistics as JSON.
+func StatsHandler(pool *pgxpool.Pool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := json.Marshal(Stats(pool))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		if _, writeErr := w.Write(body); writeErr != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package usergrpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	commonv1 "github.com/ozankenangungor/go-commerce/api/gen/go/common/v1"
+	"github.com/ozankenangungor/go-commerce/pkg/tracing"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type requestIDContextKey struct{}
+
+// requestIDMetadataKey carries the request id in gRPC metadata, for callers that forward it
+// outside the RequestContext message.
+const requestIDMetadataKey = "x-request-id"
+
+// requestContextCarrier is implemented by every users.v1 request message.
+type requestContextCarrier interface {
+	GetCtx() *commonv1.RequestContext
+}
+
+// RequestIDFromContext returns the request id attached by the request id interceptor.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// unaryInterceptors returns the server's unary interceptor chain, outermost first.
+func unaryInterceptors(logger zerolog.Logger, opts ServerOptions) []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		unaryRecoveryInterceptor(logger),
+		unaryRequestIDInterceptor(logger),
+		unaryLoggingInterceptor(),
+		unaryDeadlineInterceptor(opts.MinRemainingDeadline),
+	}
+}
+
+// unaryRecoveryInterceptor turns handler and interceptor panics into codes.Internal instead
+// of crashing the process. It must stay first in the chain.
+func unaryRecoveryInterceptor(logger zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.Error().
+					Str("method", info.FullMethod).
+					Interface("panic", recovered).
+					Bytes("stack", debug.Stack()).
+					Msg("grpc handler panic recovered")
+				resp = nil
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// unaryRequestIDInterceptor resolves the request id from the incoming RequestContext, then
+// the x-request-id metadata (or generates one) and attaches it, along with a request-scoped
+// logger that also carries the trace id and any caller origin, to the context.
+func unaryRequestIDInterceptor(logger zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var requestCtx *commonv1.RequestContext
+		if carrier, ok := req.(requestContextCarrier); ok {
+			requestCtx = carrier.GetCtx()
+		}
+
+		requestID := requestCtx.GetRequestId()
+		if requestID == "" {
+			if values := metadata.ValueFromIncomingContext(ctx, requestIDMetadataKey); len(values) > 0 {
+				requestID = values[0]
+			}
+		}
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+
+		logContext := logger.With().Str("request_id", requestID)
+		if traceID := tracing.TraceID(ctx); traceID != "" {
+			logContext = logContext.Str("trace_id", traceID)
+		}
+		// Origin fields are optional; older or internal callers leave them empty.
+		if clientIP := requestCtx.GetClientIp(); clientIP != "" {
+			logContext = logContext.Str("client_ip", clientIP)
+		}
+		if userAgent := requestCtx.GetUserAgent(); userAgent != "" {
+			logContext = logContext.Str("user_agent", userAgent)
+		}
+		requestLogger := logContext.Logger()
+		ctx = context.WithValue(ctx, requestIDContextKey{}, requestID)
+		ctx = requestLogger.WithContext(ctx)
+		return handler(ctx, req)
+	}
+}
+
+// healthMethodPrefix matches the standard gRPC health service, which orchestrators probe
+// every few seconds.
+var healthMethodPrefix = "/" + grpc_health_v1.Health_ServiceDesc.ServiceName + "/"
+
+func isHealthMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, healthMethodPrefix)
+}
+
+// unaryLoggingInterceptor logs each RPC with its method, status code, and duration. Successful
+// health probes are logged at debug so they do not flood the info stream.
+func unaryLoggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		level := zerolog.InfoLevel
+		if err == nil && isHealthMethod(info.FullMethod) {
+			level = zerolog.DebugLevel
+		}
+		zerolog.Ctx(ctx).WithLevel(level).
+			Str("method", info.FullMethod).
+			Str("code", status.Code(err).String()).
+			Dur("duration", time.Since(start)).
+			Msg("grpc_request")
+
+		return resp, err
+	}
+}
+
+// unaryDeadlineInterceptor sheds requests whose caller deadline (propagated by gRPC as
+// grpc-timeout) leaves less than minRemaining, since the caller will have given up before
+// any database work completes. Zero disables shedding. Health checks are exempt: they do no
+// database work and probes often run with tight timeouts.
+func unaryDeadlineInterceptor(minRemaining time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if minRemaining > 0 && !isHealthMethod(info.FullMethod) {
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < minRemaining {
+				return nil, status.Error(codes.DeadlineExceeded, "insufficient time remaining before caller deadline")
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func newRequestID() string {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "req-unknown"
+	}
+	return "req-" + hex.EncodeToString(raw)
+}
@@ -0,0 +1,232 @@
+package usergrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	commonv1 "github.com/ozankenangungor/go-commerce/api/gen/go/common/v1"
+	usersv1 "github.com/ozankenangungor/go-commerce/api/gen/go/users/v1"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryInterceptorsPropagateRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	chain := chainInterceptors(zerolog.New(&logs), ServerOptions{})
+
+	var seen string
+	req := &usersv1.GetProfileRequest{Ctx: &commonv1.RequestContext{RequestId: "req-from-gateway"}}
+	_, err := chain(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/GetProfile"},
+		func(ctx context.Context, req any) (any, error) {
+			seen = RequestIDFromContext(ctx)
+			return nil, status.Error(codes.NotFound, "missing")
+		})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound to pass through, got %v", err)
+	}
+
+	if seen != "req-from-gateway" {
+		t.Fatalf("expected request id req-from-gateway in handler context, got %q", seen)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal log entry: %v", err)
+	}
+	if entry["request_id"] != "req-from-gateway" {
+		t.Fatalf("expected request_id in log, got %v", entry["request_id"])
+	}
+	if entry["method"] != "/users.v1.UserService/GetProfile" {
+		t.Fatalf("unexpected method in log: %v", entry["method"])
+	}
+	if entry["code"] != codes.NotFound.String() {
+		t.Fatalf("expected code NotFound in log, got %v", entry["code"])
+	}
+}
+
+func TestUnaryInterceptorsGenerateMissingRequestID(t *testing.T) {
+	chain := chainInterceptors(zerolog.Nop(), ServerOptions{})
+
+	var seen string
+	_, err := chain(context.Background(), &usersv1.LoginRequest{}, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/Login"},
+		func(ctx context.Context, req any) (any, error) {
+			seen = RequestIDFromContext(ctx)
+			return &usersv1.LoginResponse{}, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(seen, "req-") {
+		t.Fatalf("expected generated request id, got %q", seen)
+	}
+}
+
+// chainInterceptors composes the server's unary interceptors in registration order.
+func chainInterceptors(logger zerolog.Logger, opts ServerOptions) grpc.UnaryServerInterceptor {
+	interceptors := unaryInterceptors(logger, opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor := interceptors[i]
+			current := next
+			next = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, current)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+func TestUnaryRecoveryInterceptorReturnsInternal(t *testing.T) {
+	var logs bytes.Buffer
+	chain := chainInterceptors(zerolog.New(&logs), ServerOptions{})
+
+	resp, err := chain(context.Background(), &usersv1.LoginRequest{}, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/Login"},
+		func(ctx context.Context, req any) (any, error) {
+			panic("boom")
+		})
+
+	if resp != nil {
+		t.Fatalf("expected nil response after panic, got %#v", resp)
+	}
+	grpcStatus, ok := status.FromError(err)
+	if !ok || grpcStatus.Code() != codes.Internal || grpcStatus.Message() != "internal error" {
+		t.Fatalf("expected codes.Internal \"internal error\", got %v", err)
+	}
+	if !strings.Contains(logs.String(), "grpc handler panic recovered") || !strings.Contains(logs.String(), `"panic":"boom"`) {
+		t.Fatalf("expected panic to be logged, got %q", logs.String())
+	}
+}
+
+func TestUnaryInterceptorsReadRequestIDFromMetadata(t *testing.T) {
+	chain := chainInterceptors(zerolog.Nop(), ServerOptions{})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-from-metadata"))
+
+	var seen string
+	_, err := chain(ctx, &usersv1.LoginRequest{}, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/Login"},
+		func(ctx context.Context, req any) (any, error) {
+			seen = RequestIDFromContext(ctx)
+			return &usersv1.LoginResponse{}, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen != "req-from-metadata" {
+		t.Fatalf("expected request id from metadata, got %q", seen)
+	}
+}
+
+func TestUnaryDeadlineInterceptorShedsNearlyExpiredRequests(t *testing.T) {
+	chain := chainInterceptors(zerolog.Nop(), ServerOptions{MinRemainingDeadline: 50 * time.Millisecond})
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/GetProfile"}
+
+	tests := []struct {
+		name        string
+		budget      time.Duration
+		wantCode    codes.Code
+		wantHandled bool
+	}{
+		{name: "no deadline", wantCode: codes.OK, wantHandled: true},
+		{name: "ample budget", budget: time.Second, wantCode: codes.OK, wantHandled: true},
+		{name: "nearly expired", budget: 10 * time.Millisecond, wantCode: codes.DeadlineExceeded},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.budget > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.budget)
+				defer cancel()
+			}
+
+			handled := false
+			_, err := chain(ctx, &usersv1.GetProfileRequest{}, info, func(ctx context.Context, req any) (any, error) {
+				handled = true
+				return &usersv1.GetProfileResponse{}, nil
+			})
+			if status.Code(err) != tc.wantCode {
+				t.Fatalf("expected code %v, got %v", tc.wantCode, err)
+			}
+			if handled != tc.wantHandled {
+				t.Fatalf("expected handler called=%v, got %v", tc.wantHandled, handled)
+			}
+		})
+	}
+}
+
+func TestUnaryInterceptorsLogCallerOrigin(t *testing.T) {
+	var logs bytes.Buffer
+	chain := chainInterceptors(zerolog.New(&logs), ServerOptions{})
+
+	req := &usersv1.LoginRequest{Ctx: &commonv1.RequestContext{
+		RequestId: "req-1",
+		ClientIp:  "203.0.113.7",
+		UserAgent: "shop-app/1.2",
+	}}
+	_, err := chain(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/Login"},
+		func(ctx context.Context, req any) (any, error) {
+			return &usersv1.LoginResponse{}, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal log entry: %v", err)
+	}
+	if entry["client_ip"] != "203.0.113.7" || entry["user_agent"] != "shop-app/1.2" {
+		t.Fatalf("expected caller origin in log, got %v", entry)
+	}
+}
+
+func TestUnaryLoggingInterceptorLogsHealthChecksAtDebug(t *testing.T) {
+	var logs bytes.Buffer
+	chain := chainInterceptors(zerolog.New(&logs).Level(zerolog.InfoLevel), ServerOptions{})
+
+	_, err := chain(context.Background(), &grpc_health_v1.HealthCheckRequest{}, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		func(ctx context.Context, req any) (any, error) {
+			return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected health check log below info level, got %q", logs.String())
+	}
+
+	_, _ = chain(context.Background(), &usersv1.GetProfileRequest{}, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/GetProfile"},
+		func(ctx context.Context, req any) (any, error) {
+			return &usersv1.GetProfileResponse{}, nil
+		})
+	if !strings.Contains(logs.String(), `"level":"info"`) {
+		t.Fatalf("expected regular RPCs to stay at info, got %q", logs.String())
+	}
+}
+
+func TestUnaryDeadlineInterceptorExemptsHealthChecks(t *testing.T) {
+	chain := chainInterceptors(zerolog.Nop(), ServerOptions{MinRemainingDeadline: 50 * time.Millisecond})
+
+	// A probe with less budget than MinRemainingDeadline must still be answered.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	handled := false
+	_, err := chain(ctx, &grpc_health_v1.HealthCheckRequest{}, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		func(ctx context.Context, req any) (any, error) {
+			handled = true
+			return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+		})
+	if err != nil || !handled {
+		t.Fatalf("expected health check to bypass deadline shedding, handled=%v err=%v", handled, err)
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	usersv1 "github.com/ozankenangungor/go-commerce/api/gen/go/users/v1"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	grpc_health_v1 "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -19,41 +23,132 @@ type Server struct {
 	logger       zerolog.Logger
 	grpcServer   *grpc.Server
 	healthServer *health.Server
+
+	readinessCheck    func(context.Context) error
+	readinessInterval time.Duration
+	// readinessCtx is canceled by Shutdown; it stops the watcher and aborts an in-flight check.
+	readinessCtx  context.Context
+	stopReadiness context.CancelFunc
+	readinessWG   sync.WaitGroup
+	statusMu      sync.Mutex
+	shuttingDown  bool
+}
+
+// maxReadinessCheckTimeout caps each readiness check so a wedged dependency cannot hold the
+// watcher for a whole long interval.
+const maxReadinessCheckTimeout = 2 * time.Second
+
+// ServerOptions tunes the transport limits of the gRPC server.
+type ServerOptions struct {
+	// MaxConcurrentStreams bounds the concurrent streams (in-flight RPCs) a single client
+	// connection may open, so one connection cannot monopolize the server.
+	MaxConcurrentStreams uint32
+
+	// MinRemainingDeadline rejects RPCs with DeadlineExceeded when the caller's deadline
+	// leaves less than this much time. Zero disables load shedding.
+	MinRemainingDeadline time.Duration
+
+	// MaxRecvMsgSize and MaxSendMsgSize cap message sizes in bytes. Zero keeps the gRPC
+	// defaults (4 MiB received, unlimited sent).
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+
+	// KeepaliveTime, when positive, pings idle client connections at that interval and
+	// closes them if no ack arrives within KeepaliveTimeout. KeepaliveMinTime is the
+	// shortest client ping interval tolerated; clients pinging faster are disconnected.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	KeepaliveMinTime time.Duration
+
+	// ReadinessCheck, when set, runs every ReadinessInterval while the server is serving.
+	// The health status is NOT_SERVING while it fails and SERVING again once it succeeds.
+	ReadinessCheck    func(context.Context) error
+	ReadinessInterval time.Duration
 }
 
 // NewServer configures gRPC services and returns a server.
-func NewServer(addr string, logger zerolog.Logger, userService usersv1.UserServiceServer) (*Server, error) {
+func NewServer(addr string, logger zerolog.Logger, userService usersv1.UserServiceServer, opts ServerOptions) (*Server, error) {
 	if addr == "" {
 		return nil, fmt.Errorf("grpc address is required")
 	}
 	if userService == nil {
 		return nil, fmt.Errorf("user service handler is required")
 	}
+	if opts.MaxConcurrentStreams == 0 {
+		return nil, fmt.Errorf("grpc max concurrent streams must be > 0")
+	}
+	if opts.MaxRecvMsgSize < 0 || opts.MaxSendMsgSize < 0 {
+		return nil, fmt.Errorf("grpc max message sizes must be >= 0")
+	}
+	if opts.KeepaliveTime < 0 || opts.KeepaliveTimeout < 0 || opts.KeepaliveMinTime < 0 {
+		return nil, fmt.Errorf("grpc keepalive durations must be >= 0")
+	}
+	if opts.ReadinessCheck != nil && opts.ReadinessInterval <= 0 {
+		return nil, fmt.Errorf("readiness interval must be > 0")
+	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(serverOptions(logger, opts)...)
 	healthServer := health.NewServer()
 
 	usersv1.RegisterUserServiceServer(grpcServer, userService)
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 	reflection.Register(grpcServer)
 
+	readinessCtx, stopReadiness := context.WithCancel(context.Background())
 	return &Server{
-		addr:         addr,
-		logger:       logger,
-		grpcServer:   grpcServer,
-		healthServer: healthServer,
+		addr:              addr,
+		logger:            logger,
+		grpcServer:        grpcServer,
+		healthServer:      healthServer,
+		readinessCheck:    opts.ReadinessCheck,
+		readinessInterval: opts.ReadinessInterval,
+		readinessCtx:      readinessCtx,
+		stopReadiness:     stopReadiness,
 	}, nil
 }
 
+func serverOptions(logger zerolog.Logger, opts ServerOptions) []grpc.ServerOption {
+	serverOpts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors(logger, opts)...),
+		grpc.MaxConcurrentStreams(opts.MaxConcurrentStreams),
+	}
+	if opts.MaxRecvMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(opts.MaxRecvMsgSize))
+	}
+	if opts.MaxSendMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxSendMsgSize(opts.MaxSendMsgSize))
+	}
+	if opts.KeepaliveTime > 0 {
+		serverOpts = append(serverOpts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    opts.KeepaliveTime,
+			Timeout: opts.KeepaliveTimeout,
+		}))
+	}
+	if opts.KeepaliveMinTime > 0 {
+		serverOpts = append(serverOpts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             opts.KeepaliveMinTime,
+			PermitWithoutStream: true,
+		}))
+	}
+	return serverOpts
+}
+
 // Start starts the gRPC listener.
 func (s *Server) Start() error {
 	lis, err := net.Listen("tcp", s.addr)
 	if err != nil {
 		return fmt.Errorf("listen grpc: %w", err)
 	}
+	return s.serve(lis)
+}
 
-	s.healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
-	s.healthServer.SetServingStatus(usersv1.UserService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+func (s *Server) serve(lis net.Listener) error {
+	s.setServingStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+	if s.readinessCheck != nil {
+		s.readinessWG.Add(1)
+		go s.watchReadiness()
+	}
 
 	s.logger.Info().Str("addr", s.addr).Msg("user service grpc listening")
 
@@ -65,8 +160,24 @@ func (s *Server) Start() error {
 
 // Shutdown gracefully stops the gRPC server, forcing stop if timeout is exceeded.
 func (s *Server) Shutdown(ctx context.Context) error {
-	s.healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
-	s.healthServer.SetServingStatus(usersv1.UserService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	// Stop the readiness watcher first so it cannot flip the status back to SERVING. Canceling
+	// aborts an in-flight check; the wait is still bounded by ctx in case the check ignores it.
+	s.stopReadiness()
+	s.statusMu.Lock()
+	s.shuttingDown = true
+	s.setServingStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	s.statusMu.Unlock()
+
+	watcherDone := make(chan struct{})
+	go func() {
+		s.readinessWG.Wait()
+		close(watcherDone)
+	}()
+	select {
+	case <-watcherDone:
+	case <-ctx.Done():
+		s.logger.Warn().Msg("readiness watcher did not stop before the shutdown deadline")
+	}
 
 	done := make(chan struct{})
 	go func() {
@@ -84,3 +195,59 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return ctx.Err()
 	}
 }
+
+func (s *Server) setServingStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.healthServer.SetServingStatus("", status)
+	s.healthServer.SetServingStatus(usersv1.UserService_ServiceDesc.ServiceName, status)
+}
+
+// watchReadiness runs the readiness check until Shutdown, logging only status changes.
+func (s *Server) watchReadiness() {
+	defer s.readinessWG.Done()
+
+	ticker := time.NewTicker(s.readinessInterval)
+	defer ticker.Stop()
+
+	checkTimeout := min(s.readinessInterval, maxReadinessCheckTimeout)
+
+	ready := true
+	for {
+		ctx, cancel := context.WithTimeout(s.readinessCtx, checkTimeout)
+		err := s.readinessCheck(ctx)
+		cancel()
+
+		switch {
+		case err != nil && ready:
+			if !s.setWatchedStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING) {
+				return
+			}
+			ready = false
+			s.logger.Warn().Err(err).Msg("readiness check failed, reporting NOT_SERVING")
+		case err == nil && !ready:
+			if !s.setWatchedStatus(grpc_health_v1.HealthCheckResponse_SERVING) {
+				return
+			}
+			ready = true
+			s.logger.Info().Msg("readiness check recovered, reporting SERVING")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.readinessCtx.Done():
+			return
+		}
+	}
+}
+
+// setWatchedStatus applies a status change from the readiness watcher unless Shutdown has
+// already taken over, and reports whether it did.
+func (s *Server) setWatchedStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) bool {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	if s.shuttingDown {
+		return false
+	}
+	s.setServingStatus(status)
+	return true
+}
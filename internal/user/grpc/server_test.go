@@ -0,0 +1,271 @@
+package usergrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	usersv1 "github.com/ozankenangungor/go-commerce/api/gen/go/users/v1"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	grpc_health_v1 "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type blockingUserService struct {
+	usersv1.UnimplementedUserServiceServer
+
+	active  atomic.Int32
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingUserService) GetProfile(ctx context.Context, req *usersv1.GetProfileRequest) (*usersv1.GetProfileResponse, error) {
+	s.active.Add(1)
+	defer s.active.Add(-1)
+
+	s.entered <- struct{}{}
+	<-s.release
+	return &usersv1.GetProfileResponse{}, nil
+}
+
+func TestNewServerRequiresMaxConcurrentStreams(t *testing.T) {
+	if _, err := NewServer(":0", zerolog.Nop(), &blockingUserService{}, ServerOptions{}); err == nil {
+		t.Fatal("expected error for zero max concurrent streams")
+	}
+}
+
+func TestServerLimitsConcurrentStreamsPerConnection(t *testing.T) {
+	service := &blockingUserService{
+		entered: make(chan struct{}, 2),
+		release: make(chan struct{}),
+	}
+	server, err := NewServer(":0", zerolog.Nop(), service, ServerOptions{MaxConcurrentStreams: 1})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.serve(listener)
+	}()
+	t.Cleanup(server.grpcServer.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := usersv1.NewUserServiceClient(conn)
+
+	done := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, callErr := client.GetProfile(context.Background(), &usersv1.GetProfileRequest{})
+			done <- callErr
+		}()
+	}
+
+	<-service.entered
+	// The second RPC must wait for stream quota rather than run concurrently.
+	select {
+	case <-service.entered:
+		t.Fatal("expected second stream to be held back by MaxConcurrentStreams")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if active := service.active.Load(); active != 1 {
+		t.Fatalf("expected 1 active handler, got %d", active)
+	}
+
+	close(service.release)
+	for range 2 {
+		if callErr := <-done; callErr != nil {
+			t.Fatalf("get profile: %v", callErr)
+		}
+	}
+}
+
+func TestServerReadinessTracksCheck(t *testing.T) {
+	var dbUp atomic.Bool
+
+	server, err := NewServer(":0", zerolog.Nop(), &blockingUserService{}, ServerOptions{
+		MaxConcurrentStreams: 1,
+		ReadinessCheck: func(ctx context.Context) error {
+			if !dbUp.Load() {
+				return errors.New("db unreachable")
+			}
+			return nil
+		},
+		ReadinessInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.serve(listener)
+	}()
+	t.Cleanup(server.grpcServer.Stop)
+
+	waitForHealth(t, server, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	dbUp.Store(true)
+	waitForHealth(t, server, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	waitForHealth(t, server, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestShutdownDoesNotWaitOutAWedgedReadinessCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(ctx context.Context) error
+	}{
+		{
+			name: "check honors cancellation",
+			check: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		{
+			name: "check ignores its context",
+			check: func(ctx context.Context) error {
+				time.Sleep(time.Minute)
+				return nil
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			started := make(chan struct{})
+			var once sync.Once
+			server, err := NewServer(":0", zerolog.Nop(), &blockingUserService{}, ServerOptions{
+				MaxConcurrentStreams: 1,
+				ReadinessCheck: func(ctx context.Context) error {
+					once.Do(func() { close(started) })
+					return tc.check(ctx)
+				},
+				ReadinessInterval: time.Minute,
+			})
+			if err != nil {
+				t.Fatalf("new server: %v", err)
+			}
+
+			listener := bufconn.Listen(1024 * 1024)
+			go func() {
+				_ = server.serve(listener)
+			}()
+			t.Cleanup(server.grpcServer.Stop)
+			<-started
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			_ = server.Shutdown(shutdownCtx)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("expected shutdown within its deadline, took %v", elapsed)
+			}
+			waitForHealth(t, server, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		})
+	}
+}
+
+func waitForHealth(t *testing.T, server *Server, want grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+
+	var got grpc_health_v1.HealthCheckResponse_ServingStatus
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := server.healthServer.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("health check: %v", err)
+		}
+		if got = resp.GetStatus(); got == want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected health status %v, got %v", want, got)
+}
+
+func TestServerEnforcesMaxRecvMsgSize(t *testing.T) {
+	server, err := NewServer(":0", zerolog.Nop(), &blockingUserService{}, ServerOptions{
+		MaxConcurrentStreams: 1,
+		MaxRecvMsgSize:       64,
+	})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.serve(listener)
+	}()
+	t.Cleanup(server.grpcServer.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = usersv1.NewUserServiceClient(conn).Login(ctx, &usersv1.LoginRequest{Password: strings.Repeat("x", 128)})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted for an oversized request, got %v", err)
+	}
+}
+
+func TestNewServerRejectsNegativeMessageSizes(t *testing.T) {
+	if _, err := NewServer(":0", zerolog.Nop(), &blockingUserService{}, ServerOptions{MaxConcurrentStreams: 1, MaxSendMsgSize: -1}); err == nil {
+		t.Fatal("expected error for negative max send message size")
+	}
+}
+
+func TestServerOptionsAddKeepaliveWhenConfigured(t *testing.T) {
+	base := ServerOptions{MaxConcurrentStreams: 1}
+	withKeepalive := base
+	withKeepalive.KeepaliveTime = 30 * time.Second
+	withKeepalive.KeepaliveTimeout = 10 * time.Second
+	withKeepalive.KeepaliveMinTime = 10 * time.Second
+
+	without := serverOptions(zerolog.Nop(), base)
+	with := serverOptions(zerolog.Nop(), withKeepalive)
+	if len(with) != len(without)+2 {
+		t.Fatalf("expected keepalive params and enforcement policy, got %d vs %d options", len(with), len(without))
+	}
+
+	if _, err := NewServer(":0", zerolog.Nop(), &blockingUserService{}, ServerOptions{MaxConcurrentStreams: 1, KeepaliveTime: -time.Second}); err == nil {
+		t.Fatal("expected error for negative keepalive time")
+	}
+}
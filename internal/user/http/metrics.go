@@ -0,0 +1,51 @@
+package userhttp
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	userdb "github.com/ozankenangungor/go-commerce/internal/user/db"
+)
+
+type metric struct {
+	name  string
+	kind  string
+	help  string
+	value int64
+}
+
+func poolMetrics(stats userdb.PoolStats) []metric {
+	return []metric{
+		{"user_service_db_pool_total_conns", "gauge", "Connections currently in the pool.", int64(stats.TotalConns)},
+		{"user_service_db_pool_idle_conns", "gauge", "Idle connections in the pool.", int64(stats.IdleConns)},
+		{"user_service_db_pool_acquired_conns", "gauge", "Connections currently checked out.", int64(stats.AcquiredConns)},
+		{"user_service_db_pool_constructing_conns", "gauge", "Connections being established.", int64(stats.ConstructingConns)},
+		{"user_service_db_pool_max_conns", "gauge", "Maximum pool size.", int64(stats.MaxConns)},
+		{"user_service_db_pool_acquire_total", "counter", "Successful connection acquisitions.", stats.AcquireCount},
+		{"user_service_db_pool_empty_acquire_total", "counter", "Acquisitions that had to wait for a connection.", stats.EmptyAcquireCount},
+		{"user_service_db_pool_canceled_acquire_total", "counter", "Acquisitions canceled by their context.", stats.CanceledAcquireCount},
+		{"user_service_db_pool_acquire_duration_ms_total", "counter", "Total time spent acquiring connections, in milliseconds.", stats.AcquireDurationMillis},
+		{"user_service_db_pool_new_conns_total", "counter", "Connections opened.", stats.NewConnsCount},
+		{"user_service_db_pool_max_lifetime_destroy_total", "counter", "Connections closed for exceeding their max lifetime.", stats.MaxLifetimeDestroyCount},
+		{"user_service_db_pool_max_idle_destroy_total", "counter", "Connections closed for exceeding their max idle time.", stats.MaxIdleDestroyCount},
+	}
+}
+
+// metricsHandler serves pool statistics in the Prometheus text exposition format.
+func metricsHandler(pool *pgxpool.Pool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		for _, m := range poolMetrics(userdb.Stats(pool)) {
+			fmt.Fprintf(&body, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		if _, writeErr := w.Write(body.Bytes()); writeErr != nil {
+			return
+		}
+	})
+}
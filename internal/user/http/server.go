@@ -0,0 +1,62 @@
+package userhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ozankenangungor/go-commerce/internal/user/httpserver"
+	"github.com/rs/zerolog"
+)
+
+// readinessTimeout bounds the database ping behind /readyz so a wedged pool fails the probe
+// instead of hanging it.
+const readinessTimeout = 2 * time.Second
+
+// NewServer builds the user service's probe and metrics listener, for tooling that does not
+// speak gRPC health. /healthz reports liveness, /readyz pings the database and /metrics
+// serves pool statistics in the Prometheus text format.
+func NewServer(addr string, logger zerolog.Logger, pool *pgxpool.Pool) (*httpserver.Server, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db pool is required")
+	}
+
+	return httpserver.New("http", addr, logger, accessLog(logger, newMux(pool)))
+}
+
+func newMux(pool *pgxpool.Pool) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		if err := pool.Ping(ctx); err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not_ready"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+	})
+	mux.Handle("GET /metrics", metricsHandler(pool))
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	if _, writeErr := w.Write(body); writeErr != nil {
+		return
+	}
+}
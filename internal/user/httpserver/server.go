@@ -0,0 +1,67 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Server is a plain HTTP listener for the user service's side surfaces (admin, probes). The
+// surfaces differ only in their routes; lifecycle and timeouts are shared here.
+type Server struct {
+	name       string
+	httpServer *http.Server
+	logger     zerolog.Logger
+}
+
+// New builds a listener named name (used in logs and errors) serving handler on addr.
+func New(name, addr string, logger zerolog.Logger, handler http.Handler) (*Server, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("%s address is required", name)
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("%s handler is required", name)
+	}
+
+	return &Server{
+		name: name,
+		httpServer: &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       10 * time.Second,
+			WriteTimeout:      10 * time.Second,
+			IdleTimeout:       60 * time.Second,
+		},
+		logger: logger,
+	}, nil
+}
+
+// Handler returns the listener's root handler.
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
+
+// Start listens until Shutdown is called. Errors other than a graceful close are returned
+// tagged with the listener name.
+func (s *Server) Start() error {
+	s.logger.Info().Str("listener", s.name).Str("addr", s.httpServer.Addr).Msg("user service http listener started")
+
+	err := s.httpServer.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s listener: %w", s.name, err)
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the listener.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("%s listener shutdown: %w", s.name, err)
+	}
+	return nil
+}
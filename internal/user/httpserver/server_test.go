@@ -0,0 +1,32 @@
+package httpserver
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestNewValidatesInputs(t *testing.T) {
+	handler := http.NotFoundHandler()
+
+	if _, err := New("admin", "", zerolog.Nop(), handler); err == nil || !strings.Contains(err.Error(), "admin address is required") {
+		t.Fatalf("expected missing address error, got %v", err)
+	}
+	if _, err := New("admin", "127.0.0.1:0", zerolog.Nop(), nil); err == nil {
+		t.Fatal("expected missing handler error")
+	}
+}
+
+func TestStartTagsListenErrorsWithName(t *testing.T) {
+	srv, err := New("probe", "127.0.0.1:-1", zerolog.Nop(), http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	err = srv.Start()
+	if err == nil || !strings.HasPrefix(err.Error(), "probe listener: ") {
+		t.Fatalf("expected listen error tagged with the listener name, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Package configfile lets services read settings from a YAML or JSON file in addition to
+// the environment. File keys are the same names as the environment variables, so every
+// setting can be provided either way and environment variables always win.
+package configfile
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PathEnv names the environment variable that points at the optional config file.
+const PathEnv = "CONFIG_FILE"
+
+// Source resolves configuration values from the environment first and the file second.
+// The zero Source reads the environment only.
+type Source struct {
+	file map[string]string
+}
+
+// FromEnv builds a Source, loading the file named by CONFIG_FILE when it is set.
+func FromEnv() (Source, error) {
+	path := strings.TrimSpace(os.Getenv(PathEnv))
+	if path == "" {
+		return Source{}, nil
+	}
+
+	values, err := Load(path)
+	if err != nil {
+		return Source{}, err
+	}
+	return Source{file: values}, nil
+}
+
+// Lookup returns the trimmed value for key, preferring a non-empty environment variable
+// over the file. It returns "" when neither sets the key.
+func (s Source) Lookup(key string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return strings.TrimSpace(s.file[key])
+}
+
+// Load reads a YAML (or JSON, which YAML accepts) file holding a flat mapping of setting
+// names to scalar values. Lists are joined with commas to match the list env format.
+func Load(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file %q: %w", path, err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse config file %q: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		str, err := scalarString(value)
+		if err != nil {
+			return nil, fmt.Errorf("config file %q: %s: %w", path, key, err)
+		}
+		values[key] = str
+	}
+	return values, nil
+}
+
+func scalarString(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			str, err := scalarString(item)
+			if err != nil {
+				return "", err
+			}
+			if _, nested := item.([]any); nested {
+				return "", fmt.Errorf("nested lists are not supported")
+			}
+			items = append(items, str)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
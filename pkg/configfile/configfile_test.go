@@ -0,0 +1,83 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadYAML(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+LOG_LEVEL: debug
+RATE_LIMIT_RPS: 2.5
+CORS_ALLOWED_ORIGINS:
+  - https://a.example
+  - https://b.example
+`)
+
+	values, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	want := map[string]string{
+		"LOG_LEVEL":            "debug",
+		"RATE_LIMIT_RPS":       "2.5",
+		"CORS_ALLOWED_ORIGINS": "https://a.example,https://b.example",
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Fatalf("expected %s=%q, got %q", key, value, values[key])
+		}
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	path := writeFile(t, "config.json", `{"USER_DB_MAX_CONNS": 20, "OTEL_EXPORTER_OTLP_INSECURE": true}`)
+
+	values, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if values["USER_DB_MAX_CONNS"] != "20" || values["OTEL_EXPORTER_OTLP_INSECURE"] != "true" {
+		t.Fatalf("unexpected values %v", values)
+	}
+}
+
+func TestLoadRejectsNestedMappings(t *testing.T) {
+	path := writeFile(t, "config.yaml", "db:\n  dsn: postgres://\n")
+
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for nested mapping")
+	}
+}
+
+func TestSourceEnvironmentOverridesFile(t *testing.T) {
+	t.Setenv(PathEnv, writeFile(t, "config.yaml", "LOG_LEVEL: debug\nLOG_FORMAT: console\n"))
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_FORMAT", "")
+
+	src, err := FromEnv()
+	if err != nil {
+		t.Fatalf("from env: %v", err)
+	}
+	if got := src.Lookup("LOG_LEVEL"); got != "warn" {
+		t.Fatalf("expected environment to win, got %q", got)
+	}
+	if got := src.Lookup("LOG_FORMAT"); got != "console" {
+		t.Fatalf("expected file value when env is empty, got %q", got)
+	}
+	if got := src.Lookup("UNSET_KEY"); got != "" {
+		t.Fatalf("expected empty value for unset key, got %q", got)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
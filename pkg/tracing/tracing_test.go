@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSetupWithoutEndpointIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Options{ServiceName: "test"})
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	if fields := otel.GetTextMapPropagator().Fields(); len(fields) == 0 {
+		t.Fatal("expected trace context propagator to be installed")
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	if got := TraceID(context.Background()); got != "" {
+		t.Fatalf("expected empty trace id without span, got %q", got)
+	}
+
+	provider := sdktrace.NewTracerProvider()
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	if got, want := TraceID(ctx), span.SpanContext().TraceID().String(); got != want {
+		t.Fatalf("expected trace id %q, got %q", want, got)
+	}
+}